@@ -4,11 +4,18 @@ package app
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cfagudelo96/toggle-test/deck/handler"
 	"github.com/cfagudelo96/toggle-test/deck/repository"
@@ -17,9 +24,36 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+const (
+	storageEnv        = "DECK_STORAGE"
+	storageDirEnv     = "DECK_STORAGE_DIR"
+	idempotencyTTLEnv = "IDEMPOTENCY_TTL"
+	deckTTLEnv        = "DECK_TTL"
+	sweepIntervalEnv  = "DECK_SWEEP_INTERVAL"
+	idleTimeoutEnv    = "DECK_IDLE_TIMEOUT"
+	cacheMaxDecksEnv  = "DECK_CACHE_MAX_DECKS"
+	cacheMaxBytesEnv  = "DECK_CACHE_MAX_BYTES"
+	debugVarsEnv      = "DECK_DEBUG_VARS"
+	adminTokenEnv     = "DECK_ADMIN_TOKEN"
+
+	memoryStorage = "memory"
+	fileStorage   = "file"
+	sqliteStorage = "sqlite"
+
+	sqliteFileName = "decks.db"
+
+	defaultStorageDir = "data"
+
+	defaultIdempotencyTTL = 24 * time.Hour
+	defaultSweepInterval  = time.Minute
+	defaultIdleTimeout    = time.Hour
+)
+
 // App represents the web application.
 type App struct {
 	Server *echo.Echo
+	// closers are the resources to be released when the app stops.
+	closers []io.Closer
 }
 
 // NewApp creates a new app and leaves it ready for execution.
@@ -41,13 +75,201 @@ func (a *App) setupServer() {
 }
 
 func (a *App) setupRoutes() {
-	dr := repository.NewInMemoryDeckRepository()
-	ds := service.NewDeckService(dr)
+	dr, is := a.newStorage()
+	ds := service.NewDeckService(dr, service.WithDefaultTTL(durationEnv(deckTTLEnv, 0)))
+	a.closers = append(a.closers, ds.StartSweeper(durationEnv(sweepIntervalEnv, defaultSweepInterval),
+		durationEnv(idleTimeoutEnv, defaultIdleTimeout)))
 	dh := handler.NewDeckEchoHandler(ds)
-	apiGroup := a.Server.Group("/v1/decks")
-	apiGroup.POST("", dh.HandleCreateDeck)
+	idempotent := handler.IdempotencyMiddleware(is, durationEnv(idempotencyTTLEnv, defaultIdempotencyTTL))
+	apiGroup := a.Server.Group("/v1/decks", handler.RequestIDMiddleware, handler.PlayerMiddleware,
+		handler.IfMatchMiddleware)
+	apiGroup.POST("", dh.HandleCreateDeck, idempotent)
 	apiGroup.GET("/:uuid", dh.HandleOpenDeck)
-	apiGroup.POST("/:uuid/draw", dh.HandleDrawCars)
+	apiGroup.DELETE("/:uuid", dh.HandleDeleteDeck)
+	apiGroup.POST("/:uuid/close", dh.HandleCloseDeck)
+	apiGroup.POST("/:uuid/draw", dh.HandleDrawCars, idempotent)
+	apiGroup.GET("/:uuid/peek", dh.HandlePeekCards)
+	apiGroup.PUT("/:uuid/visibility", dh.HandleSetDeckVisibility)
+	apiGroup.POST("/:uuid/deal", dh.HandleDealCards, idempotent)
+	apiGroup.GET("/:uuid/hands/:player", dh.HandleListHand)
+	apiGroup.PUT("/:uuid/hands/:player/visibility", dh.HandleSetHandVisibility)
+	apiGroup.GET("/:uuid/reveal", dh.HandleRevealDeck)
+	apiGroup.GET("/:uuid/history", dh.HandleHistory)
+	apiGroup.GET("/:uuid/history/:number", dh.HandleStateAt)
+	apiGroup.POST("/:uuid/return", dh.HandleReturnCards)
+	apiGroup.POST("/:uuid/cut", dh.HandleCutDeck)
+	apiGroup.POST("/:uuid/riffle", dh.HandleRiffleShuffle)
+	apiGroup.POST("/:uuid/overhand", dh.HandleOverhandShuffle)
+	apiGroup.POST("/:uuid/reshuffle", dh.HandleReshuffleDeck)
+	apiGroup.POST("/:uuid/reset", dh.HandleResetDeck)
+	apiGroup.POST("/:uuid/undo", dh.HandleUndoDeck)
+	apiGroup.GET("/:uuid/piles/:pile", dh.HandleListPile)
+	apiGroup.POST("/:uuid/piles/:pile/add", dh.HandleAddToPile)
+	apiGroup.POST("/:uuid/piles/:pile/draw", dh.HandleDrawFromPile)
+	apiGroup.POST("/:uuid/piles/:pile/shuffle", dh.HandleShufflePile)
+	apiGroup.PUT("/:uuid/piles/:pile/visibility", dh.HandleSetPileVisibility)
+
+	// Listing enumerates the decks of every player, so it is only served to the operators holding the admin token.
+	if token := os.Getenv(adminTokenEnv); token != "" {
+		apiGroup.GET("", dh.HandleListDecks, handler.AdminMiddleware(token))
+	}
+
+	// The variables expose the command line and the memory statistics of the process, so serving them is opt-in.
+	if boolEnv(debugVarsEnv) {
+		a.Server.GET("/debug/vars", echo.WrapHandler(expvar.Handler()))
+	}
+}
+
+// newStorage returns the deck repository and the idempotency store configured through the DECK_STORAGE environment
+// variable. By default the decks are stored in memory. The idempotency keys are stored along with the decks when
+// using the file or the SQLite storage, and in memory otherwise.
+// If DECK_CACHE_MAX_DECKS or DECK_CACHE_MAX_BYTES are set, the decks held in memory are bounded, evicting the least
+// recently used ones, and the SQLite decks are cached in memory up to those bounds. The file storage holds every deck
+// in memory, so it can't be bounded.
+func (a *App) newStorage() (service.DeckRepository, service.IdempotencyStore) {
+	dir := os.Getenv(storageDirEnv)
+
+	if dir == "" {
+		dir = defaultStorageDir
+	}
+
+	cacheOpts := []repository.LRUOption{
+		repository.MaxDecks(intEnv(cacheMaxDecksEnv)),
+		repository.MaxBytes(int64(intEnv(cacheMaxBytesEnv))),
+	}
+	cached := os.Getenv(cacheMaxDecksEnv) != "" || os.Getenv(cacheMaxBytesEnv) != ""
+
+	switch storage := os.Getenv(storageEnv); storage {
+	case "", memoryStorage:
+		if cached {
+			return publishStats(repository.NewLRUDeckRepository(cacheOpts...)), repository.NewInMemoryIdempotencyStore()
+		}
+
+		return repository.NewInMemoryDeckRepository(), repository.NewInMemoryIdempotencyStore()
+	case fileStorage:
+		if cached {
+			log.Fatalf("Invalid %s or %s, the file storage holds every deck in memory", cacheMaxDecksEnv,
+				cacheMaxBytesEnv)
+		}
+
+		r, err := repository.NewFileDeckRepository(dir)
+
+		if err != nil {
+			log.Fatalf("Error opening the file storage: %v", err)
+		}
+
+		is, err := repository.NewFileIdempotencyStore(dir, time.Now())
+
+		if err != nil {
+			log.Fatalf("Error opening the file idempotency store: %v", err)
+		}
+
+		a.closers = append(a.closers, r, is)
+
+		return r, is
+	case sqliteStorage:
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Fatalf("Error creating the storage directory: %v", err)
+		}
+
+		r, err := repository.NewSQLiteDeckRepository(context.Background(), filepath.Join(dir, sqliteFileName))
+
+		if err != nil {
+			log.Fatalf("Error opening the SQLite storage: %v", err)
+		}
+
+		a.closers = append(a.closers, r)
+
+		if cached {
+			cache := repository.NewLRUDeckRepository(append(cacheOpts, repository.ReadThrough(r))...)
+
+			return publishStats(cache), r.IdempotencyStore()
+		}
+
+		return r, r.IdempotencyStore()
+	default:
+		log.Fatalf("Unknown storage %q", storage)
+		return nil, nil
+	}
+}
+
+// durationEnv returns the duration configured through the given environment variable, such as 1h, or the default
+// duration if the variable isn't set. These are:
+//   - IDEMPOTENCY_TTL: for how long the responses of the requests with an idempotency key are kept, a day by default.
+//   - DECK_TTL: the time to live of the decks created without one, by default they never expire.
+//   - DECK_SWEEP_INTERVAL: how often the expired and idle decks are deleted, every minute by default.
+//   - DECK_IDLE_TIMEOUT: for how long exhausted and closed decks are kept after their last change, an hour by default.
+func durationEnv(env string, def time.Duration) time.Duration {
+	str := os.Getenv(env)
+
+	if str == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(str)
+
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s %q, must be a positive duration", env, str)
+	}
+
+	return d
+}
+
+// intEnv returns the number configured through the given environment variable, or 0 if the variable isn't set.
+// These are:
+//   - DECK_CACHE_MAX_DECKS: the maximum number of decks held in memory, unbounded by default.
+//   - DECK_CACHE_MAX_BYTES: the maximum estimated bytes of the decks held in memory, unbounded by default.
+func intEnv(env string) int {
+	str := os.Getenv(env)
+
+	if str == "" {
+		return 0
+	}
+
+	n, err := strconv.Atoi(str)
+
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s %q, must be a positive number", env, str)
+	}
+
+	return n
+}
+
+// boolEnv returns whether the feature configured through the given environment variable is enabled, false if the
+// variable isn't set. These are:
+//   - DECK_DEBUG_VARS: whether the variables of the process, such as the deck cache counters, are served at
+//     /debug/vars, disabled by default.
+func boolEnv(env string) bool {
+	str := os.Getenv(env)
+
+	if str == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(str)
+
+	if err != nil {
+		log.Fatalf("Invalid %s %q, must be true or false", env, str)
+	}
+
+	return b
+}
+
+var (
+	publishStatsOnce sync.Once
+	// cacheStats is the deck cache whose counters are published, the one of the last app created.
+	cacheStats atomic.Pointer[repository.LRUDeckRepository]
+)
+
+// publishStats publishes the counters of the deck cache in the deck_cache variable served at /debug/vars.
+// The variable can only be published once per process, so it reports the cache of the last app created.
+func publishStats(r *repository.LRUDeckRepository) *repository.LRUDeckRepository {
+	cacheStats.Store(r)
+	publishStatsOnce.Do(func() {
+		expvar.Publish("deck_cache", expvar.Func(func() any { return cacheStats.Load().Stats() }))
+	})
+
+	return r
 }
 
 // StartApp initializes the server.
@@ -78,4 +300,11 @@ func (a *App) stopApp() {
 	if err := a.Server.Shutdown(ctx); err != nil {
 		log.Fatalf("Error shutting down the server: %v", err)
 	}
+
+	// The resources are released in the reverse order they were acquired, as they may depend on the previous ones.
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		if err := a.closers[i].Close(); err != nil {
+			log.Printf("Error releasing the app resources: %v", err)
+		}
+	}
 }
@@ -0,0 +1,405 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidCardCode error returned when a card code can't be parsed.
+	ErrInvalidCardCode = errors.New("invalid_card_code")
+	// ErrInvalidRank error returned when a rank code can't be parsed.
+	ErrInvalidRank = errors.New("invalid_rank")
+	// ErrInvalidSuit error returned when a suit code can't be parsed.
+	ErrInvalidSuit = errors.New("invalid_suit")
+)
+
+// Rank represents the rank of a card. Ranks are numerically ordered from Ace to King.
+type Rank int
+
+// Ranks of a french deck.
+const (
+	Ace Rank = iota + 1
+	Two
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	// Joker is the rank of the jokers, which don't belong to any suit.
+	Joker
+)
+
+const (
+	aceHighValue   = 14
+	jokerHighValue = 15
+	jokerCode      = "X"
+)
+
+// Ranks returns every rank of a french deck ordered from Ace to King. Jokers are not included.
+func Ranks() []Rank {
+	return []Rank{Ace, Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King}
+}
+
+// ParseRank returns the rank represented by the code given (A, 2-10, T, J, Q, K or X for jokers).
+func ParseRank(code string) (Rank, error) {
+	switch code {
+	case jokerCode:
+		return Joker, nil
+	case "A":
+		return Ace, nil
+	case "J":
+		return Jack, nil
+	case "Q":
+		return Queen, nil
+	case "K":
+		return King, nil
+	case "T", "10":
+		return Ten, nil
+	case "2", "3", "4", "5", "6", "7", "8", "9":
+		n, _ := strconv.Atoi(code)
+		return Rank(n), nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRank, code)
+	}
+}
+
+// Code returns the code used to represent the rank inside a card code.
+func (r Rank) Code() string {
+	switch r {
+	case Ace:
+		return "A"
+	case Jack:
+		return "J"
+	case Queen:
+		return "Q"
+	case King:
+		return "K"
+	case Joker:
+		return jokerCode
+	default:
+		return strconv.Itoa(int(r))
+	}
+}
+
+// String returns the name of the rank as exposed in the card values.
+func (r Rank) String() string {
+	switch r {
+	case Ace:
+		return "ACE"
+	case Jack:
+		return "JACK"
+	case Queen:
+		return "QUEEN"
+	case King:
+		return "KING"
+	case Joker:
+		return "JOKER"
+	default:
+		return strconv.Itoa(int(r))
+	}
+}
+
+// AceHighValue returns the numeric value of the rank when aces rank above kings. Jokers rank above aces.
+func (r Rank) AceHighValue() int {
+	switch r {
+	case Ace:
+		return aceHighValue
+	case Joker:
+		return jokerHighValue
+	default:
+		return int(r)
+	}
+}
+
+// Suit represents the suit of a card. Suits are numerically ordered as Clubs, Diamonds, Hearts and Spades.
+type Suit int
+
+// Suits of a french deck. Jokers have NoSuit.
+const (
+	NoSuit Suit = iota
+	Clubs
+	Diamonds
+	Hearts
+	Spades
+)
+
+// Suits returns every suit of a french deck in their natural order. NoSuit is not included.
+func Suits() []Suit {
+	return []Suit{Clubs, Diamonds, Hearts, Spades}
+}
+
+// ParseSuit returns the suit represented by the code given (C, D, H or S).
+func ParseSuit(code string) (Suit, error) {
+	switch code {
+	case "C":
+		return Clubs, nil
+	case "D":
+		return Diamonds, nil
+	case "H":
+		return Hearts, nil
+	case "S":
+		return Spades, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSuit, code)
+	}
+}
+
+// Code returns the code used to represent the suit inside a card code.
+func (s Suit) Code() string {
+	switch s {
+	case Clubs:
+		return "C"
+	case Diamonds:
+		return "D"
+	case Hearts:
+		return "H"
+	case Spades:
+		return "S"
+	default:
+		return ""
+	}
+}
+
+// String returns the name of the suit as exposed in the cards.
+func (s Suit) String() string {
+	switch s {
+	case Clubs:
+		return "CLUBS"
+	case Diamonds:
+		return "DIAMONDS"
+	case Hearts:
+		return "HEARTS"
+	case Spades:
+		return "SPADES"
+	default:
+		return "NONE"
+	}
+}
+
+// Card represents a card inside a french deck.
+type Card struct {
+	Rank Rank
+	Suit Suit
+	// DeckIndex identifies the deck of a multi-deck shoe the card belongs to, starting from 1.
+	// It is 0 for the cards that don't belong to a shoe.
+	DeckIndex int
+}
+
+// NewJoker returns a joker card.
+func NewJoker() Card {
+	return Card{Rank: Joker, Suit: NoSuit}
+}
+
+// Code returns the code of the card, composed by the rank code followed by the suit code. Jokers have the code X.
+func (c Card) Code() string {
+	return c.Rank.Code() + c.Suit.Code()
+}
+
+// String returns the code of the card.
+func (c Card) String() string {
+	return c.Code()
+}
+
+type cardJSON struct {
+	Value     string `json:"value"`
+	Suit      string `json:"suit"`
+	Code      string `json:"code"`
+	DeckIndex int    `json:"deck_index,omitempty"`
+}
+
+// MarshalJSON encodes the card with its value, suit and code, and its deck index if it belongs to a shoe.
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cardJSON{
+		Value:     c.Rank.String(),
+		Suit:      c.Suit.String(),
+		Code:      c.Code(),
+		DeckIndex: c.DeckIndex,
+	})
+}
+
+// UnmarshalJSON decodes the card from its code and deck index.
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var cj cardJSON
+
+	if err := json.Unmarshal(data, &cj); err != nil {
+		return err
+	}
+
+	parsed, err := FromCode(cj.Code)
+
+	if err != nil {
+		return err
+	}
+
+	parsed.DeckIndex = cj.DeckIndex
+	*c = parsed
+
+	return nil
+}
+
+// CompleteDeckCards returns a complete set of french deck cards sorted.
+func CompleteDeckCards() []Card {
+	suits := Suits()
+	ranks := Ranks()
+	cards := make([]Card, 0, len(ranks)*len(suits))
+
+	for _, s := range suits {
+		for _, r := range ranks {
+			cards = append(cards, Card{Rank: r, Suit: s})
+		}
+	}
+
+	return cards
+}
+
+// CardComparator compares two cards. Returns a negative number if a goes before b, a positive number
+// if a goes after b and zero if both are equivalent.
+type CardComparator func(a, b Card) int
+
+var (
+	// ByRank compares cards by rank with aces ranking below twos.
+	ByRank CardComparator = func(a, b Card) int {
+		return int(a.Rank) - int(b.Rank)
+	}
+	// ByRankAceHigh compares cards by rank with aces ranking above kings.
+	ByRankAceHigh CardComparator = func(a, b Card) int {
+		return a.Rank.AceHighValue() - b.Rank.AceHighValue()
+	}
+	// BySuit compares cards by suit.
+	BySuit CardComparator = func(a, b Card) int {
+		return int(a.Suit) - int(b.Suit)
+	}
+)
+
+// Then returns a comparator that uses next to break the ties of the comparator.
+func (cmp CardComparator) Then(next CardComparator) CardComparator {
+	return func(a, b Card) int {
+		if r := cmp(a, b); r != 0 {
+			return r
+		}
+
+		return next(a, b)
+	}
+}
+
+// SortCards sorts the cards given in place using the comparator. Equivalent cards keep their relative order.
+func SortCards(cards []Card, cmp CardComparator) {
+	sort.SliceStable(cards, func(i, j int) bool { return cmp(cards[i], cards[j]) < 0 })
+}
+
+// CardCodeError represents a card code that couldn't be parsed.
+type CardCodeError struct {
+	Position int
+	Code     string
+	Reason   string
+}
+
+func (e *CardCodeError) Error() string {
+	return fmt.Sprintf("invalid card code %q at position %d: %s", e.Code, e.Position, e.Reason)
+}
+
+// Unwrap allows to match the error against ErrInvalidCardCode.
+func (e *CardCodeError) Unwrap() error {
+	return ErrInvalidCardCode
+}
+
+// InvalidCardsError groups every card code that couldn't be parsed from a list of codes.
+type InvalidCardsError struct {
+	Errors []*CardCodeError
+}
+
+func (e *InvalidCardsError) Error() string {
+	reasons := make([]string, len(e.Errors))
+
+	for i, err := range e.Errors {
+		reasons[i] = err.Error()
+	}
+
+	return strings.Join(reasons, "; ")
+}
+
+// Unwrap allows to match the error against ErrInvalidCardCode.
+func (e *InvalidCardsError) Unwrap() error {
+	return ErrInvalidCardCode
+}
+
+// FromCode returns the card represented by the code given as a parameter.
+// The code is composed by the rank (A, 2-10, T, J, Q or K) followed by the suit (C, D, H or S), or is X for jokers.
+// Returns a *CardCodeError if the code is empty or the rank or the suit are unknown.
+func FromCode(code string) (Card, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+
+	if normalized == "" {
+		return Card{}, &CardCodeError{Code: code, Reason: "the code is empty"}
+	}
+
+	if normalized == jokerCode {
+		return NewJoker(), nil
+	}
+
+	if len(normalized) < 2 {
+		return Card{}, &CardCodeError{Code: code, Reason: "the code must have a rank and a suit"}
+	}
+
+	rankPart, suitPart := normalized[:len(normalized)-1], normalized[len(normalized)-1:]
+
+	rank, err := ParseRank(rankPart)
+
+	if err != nil {
+		return Card{}, &CardCodeError{Code: code, Reason: fmt.Sprintf("unknown rank %q", rankPart)}
+	}
+
+	if rank == Joker {
+		return Card{}, &CardCodeError{Code: code, Reason: "jokers don't have a suit"}
+	}
+
+	suit, err := ParseSuit(suitPart)
+
+	if err != nil {
+		return Card{}, &CardCodeError{Code: code, Reason: fmt.Sprintf("unknown suit %q", suitPart)}
+	}
+
+	return Card{Rank: rank, Suit: suit}, nil
+}
+
+// FromCodes returns the cards represented by the codes given as a parameter, keeping their order.
+// Returns an *InvalidCardsError listing every code that couldn't be parsed along with its position.
+func FromCodes(codes []string) ([]Card, error) {
+	cards := make([]Card, len(codes))
+
+	var invalid []*CardCodeError
+
+	for i, code := range codes {
+		c, err := FromCode(code)
+
+		if err != nil {
+			var codeErr *CardCodeError
+			if errors.As(err, &codeErr) {
+				codeErr.Position = i
+				invalid = append(invalid, codeErr)
+				continue
+			}
+
+			return nil, err
+		}
+
+		cards[i] = c
+	}
+
+	if len(invalid) > 0 {
+		return nil, &InvalidCardsError{Errors: invalid}
+	}
+
+	return cards, nil
+}
@@ -0,0 +1,141 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestCard_MarshalJSON(t *testing.T) {
+	t.Run("keeps the value, suit and code shape", func(t *testing.T) {
+		got, err := json.Marshal(domain.Card{Rank: domain.Ten, Suit: domain.Hearts})
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		want := `{"value":"10","suit":"HEARTS","code":"10H"}`
+		if string(got) != want {
+			t.Errorf("json.Marshal() = %s, want %s", got, want)
+		}
+	})
+	t.Run("encodes jokers without suit", func(t *testing.T) {
+		got, err := json.Marshal(domain.NewJoker())
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		want := `{"value":"JOKER","suit":"NONE","code":"X"}`
+		if string(got) != want {
+			t.Errorf("json.Marshal() = %s, want %s", got, want)
+		}
+	})
+	t.Run("decodes what it encodes", func(t *testing.T) {
+		want := domain.Card{Rank: domain.Queen, Suit: domain.Clubs}
+		data, _ := json.Marshal(want)
+		var got domain.Card
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("json.Unmarshal() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestParseRank(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    domain.Rank
+		wantErr bool
+	}{
+		{code: "A", want: domain.Ace},
+		{code: "7", want: domain.Seven},
+		{code: "T", want: domain.Ten},
+		{code: "10", want: domain.Ten},
+		{code: "K", want: domain.King},
+		{code: "1", wantErr: true},
+		{code: "Z", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := domain.ParseRank(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRank() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseRank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSuit(t *testing.T) {
+	for _, s := range domain.Suits() {
+		got, err := domain.ParseSuit(s.Code())
+		if err != nil || got != s {
+			t.Errorf("ParseSuit(%q) = %v, %v, want %v", s.Code(), got, err, s)
+		}
+	}
+	if _, err := domain.ParseSuit("X"); err == nil {
+		t.Error("ParseSuit() should fail with an unknown suit")
+	}
+}
+
+func TestSortCards(t *testing.T) {
+	cards := func() []domain.Card {
+		return []domain.Card{
+			{Rank: domain.King, Suit: domain.Spades},
+			{Rank: domain.Ace, Suit: domain.Hearts},
+			{Rank: domain.Two, Suit: domain.Clubs},
+			{Rank: domain.Ace, Suit: domain.Clubs},
+		}
+	}
+	tests := []struct {
+		name string
+		cmp  domain.CardComparator
+		want []domain.Card
+	}{
+		{
+			name: "by rank with aces low",
+			cmp:  domain.ByRank,
+			want: []domain.Card{
+				{Rank: domain.Ace, Suit: domain.Hearts},
+				{Rank: domain.Ace, Suit: domain.Clubs},
+				{Rank: domain.Two, Suit: domain.Clubs},
+				{Rank: domain.King, Suit: domain.Spades},
+			},
+		},
+		{
+			name: "by rank with aces high",
+			cmp:  domain.ByRankAceHigh,
+			want: []domain.Card{
+				{Rank: domain.Two, Suit: domain.Clubs},
+				{Rank: domain.King, Suit: domain.Spades},
+				{Rank: domain.Ace, Suit: domain.Hearts},
+				{Rank: domain.Ace, Suit: domain.Clubs},
+			},
+		},
+		{
+			name: "by suit then rank",
+			cmp:  domain.BySuit.Then(domain.ByRank),
+			want: []domain.Card{
+				{Rank: domain.Ace, Suit: domain.Clubs},
+				{Rank: domain.Two, Suit: domain.Clubs},
+				{Rank: domain.Ace, Suit: domain.Hearts},
+				{Rank: domain.King, Suit: domain.Spades},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := cards()
+			domain.SortCards(got, tt.cmp)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortCards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDeckClosed error returned when modifying a deck that was closed.
+	ErrDeckClosed = errors.New("deck_closed")
+)
+
+// Close freezes the deck at the given time, so it can be viewed but not modified anymore.
+// Returns an error if the deck is already closed.
+func (d *Deck) Close(at time.Time) error {
+	if d.Closed() {
+		return ErrDeckClosed
+	}
+
+	d.ClosedAt = &at
+	d.Change = &EventParams{}
+
+	return nil
+}
+
+// Closed returns whether the deck was closed.
+func (d *Deck) Closed() bool {
+	return d.ClosedAt != nil
+}
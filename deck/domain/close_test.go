@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_Close(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+
+	if d.Closed() {
+		t.Error("Deck.Closed() = true, want new decks to be open")
+	}
+	if err := d.Close(at); err != nil {
+		t.Fatalf("Deck.Close() error = %v", err)
+	}
+	if !d.Closed() || !d.ClosedAt.Equal(at) {
+		t.Errorf("Deck.ClosedAt = %v, want %v", d.ClosedAt, at)
+	}
+	if err := d.Close(at.Add(time.Hour)); !errors.Is(err, domain.ErrDeckClosed) {
+		t.Errorf("Deck.Close() error = %v, want %v", err, domain.ErrDeckClosed)
+	}
+	if c := d.Clone(); c.ClosedAt == d.ClosedAt || !c.ClosedAt.Equal(at) {
+		t.Errorf("Deck.Clone().ClosedAt = %v, want a copy of %v", c.ClosedAt, at)
+	}
+}
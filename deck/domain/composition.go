@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidComposition error returned when a deck composition is invalid or doesn't produce any card.
+	ErrInvalidComposition = errors.New("invalid_composition")
+)
+
+const (
+	// MaxShoeDecks is the maximum number of decks in a multi-deck shoe.
+	MaxShoeDecks = 16
+	// MaxJokers is the maximum number of jokers added to every deck.
+	MaxJokers = 8
+)
+
+// Composition describes the cards making up a deck. The zero value describes a complete french deck without jokers.
+type Composition struct {
+	// Decks is the number of decks combined in a multi-deck shoe. A value of 0 or 1 means a single deck,
+	// otherwise every card gets the index of its deck.
+	Decks int
+	// Jokers is the number of jokers added at the end of every deck.
+	Jokers int
+	// Suits restricts the deck to the suits given. Every suit is included if empty.
+	Suits []Suit
+	// LowestRank and HighestRank restrict the deck to an inclusive range of ranks. An Ace as the lowest rank
+	// ranks below twos, and as the highest rank ranks above kings, so both 7 through Ace and Ace through 5 are valid.
+	// The range isn't restricted if both are zero.
+	LowestRank  Rank
+	HighestRank Rank
+	// ExcludedRanks are removed from the deck.
+	ExcludedRanks []Rank
+}
+
+// Cards returns the cards of the composition sorted by suit and then by rank, followed by the jokers.
+// The cards of a multi-deck shoe are sorted deck after deck.
+// Returns an error if the composition is invalid or doesn't produce any card.
+func (c Composition) Cards() ([]Card, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	suits := c.Suits
+
+	if len(suits) == 0 {
+		suits = Suits()
+	}
+
+	var cards []Card
+
+	for _, s := range Suits() {
+		if !containsSuit(suits, s) {
+			continue
+		}
+
+		for _, r := range Ranks() {
+			if c.includesRank(r) {
+				cards = append(cards, Card{Rank: r, Suit: s})
+			}
+		}
+	}
+
+	for i := 0; i < c.Jokers; i++ {
+		cards = append(cards, NewJoker())
+	}
+
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("%w: the composition doesn't have any card", ErrInvalidComposition)
+	}
+
+	if c.Decks <= 1 {
+		return cards, nil
+	}
+
+	shoe := make([]Card, 0, len(cards)*c.Decks)
+
+	for i := 1; i <= c.Decks; i++ {
+		for _, card := range cards {
+			card.DeckIndex = i
+			shoe = append(shoe, card)
+		}
+	}
+
+	return shoe, nil
+}
+
+func (c Composition) validate() error {
+	if c.Decks < 0 || c.Decks > MaxShoeDecks {
+		return fmt.Errorf("%w: the number of decks must be between 1 and %d", ErrInvalidComposition, MaxShoeDecks)
+	}
+
+	if c.Jokers < 0 || c.Jokers > MaxJokers {
+		return fmt.Errorf("%w: the number of jokers must be between 0 and %d", ErrInvalidComposition, MaxJokers)
+	}
+
+	for _, s := range c.Suits {
+		if s < Clubs || s > Spades {
+			return fmt.Errorf("%w: unknown suit %d", ErrInvalidComposition, s)
+		}
+	}
+
+	if (c.LowestRank == 0) != (c.HighestRank == 0) {
+		return fmt.Errorf("%w: the rank range needs both the lowest and the highest rank", ErrInvalidComposition)
+	}
+
+	for _, r := range append([]Rank{c.LowestRank, c.HighestRank}, c.ExcludedRanks...) {
+		if r == Joker || r < 0 || r > King {
+			return fmt.Errorf("%w: unknown rank %d", ErrInvalidComposition, r)
+		}
+	}
+
+	if c.LowestRank != 0 && c.lowestValue() > c.highestValue() {
+		return fmt.Errorf("%w: the lowest rank %s is above the highest rank %s",
+			ErrInvalidComposition, c.LowestRank, c.HighestRank)
+	}
+
+	return nil
+}
+
+func (c Composition) lowestValue() int {
+	return int(c.LowestRank)
+}
+
+func (c Composition) highestValue() int {
+	return c.HighestRank.AceHighValue()
+}
+
+func (c Composition) includesRank(r Rank) bool {
+	for _, excluded := range c.ExcludedRanks {
+		if r == excluded {
+			return false
+		}
+	}
+
+	if c.LowestRank == 0 {
+		return true
+	}
+
+	low, high := c.lowestValue(), c.highestValue()
+
+	return (low <= int(r) && int(r) <= high) || (low <= r.AceHighValue() && r.AceHighValue() <= high)
+}
+
+func containsSuit(suits []Suit, s Suit) bool {
+	for _, candidate := range suits {
+		if candidate == s {
+			return true
+		}
+	}
+
+	return false
+}
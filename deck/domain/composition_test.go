@@ -0,0 +1,126 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func codes(cards []domain.Card) []string {
+	c := make([]string, len(cards))
+	for i, card := range cards {
+		c[i] = card.Code()
+	}
+	return c
+}
+
+func TestComposition_Cards(t *testing.T) {
+	tests := []struct {
+		name        string
+		composition domain.Composition
+		wantLen     int
+		wantFirst   string
+		wantLast    string
+		wantErr     bool
+	}{
+		{
+			name:        "the zero value is a complete deck",
+			composition: domain.Composition{},
+			wantLen:     52,
+			wantFirst:   "AC",
+			wantLast:    "KS",
+		},
+		{
+			name:        "adds jokers at the end",
+			composition: domain.Composition{Jokers: 2},
+			wantLen:     54,
+			wantFirst:   "AC",
+			wantLast:    "X",
+		},
+		{
+			name:        "works with a piquet deck from 7 through Ace",
+			composition: domain.Composition{LowestRank: domain.Seven, HighestRank: domain.Ace},
+			wantLen:     32,
+			wantFirst:   "AC",
+			wantLast:    "KS",
+		},
+		{
+			name:        "works with a range from Ace through 5",
+			composition: domain.Composition{LowestRank: domain.Ace, HighestRank: domain.Five},
+			wantLen:     20,
+			wantFirst:   "AC",
+			wantLast:    "5S",
+		},
+		{
+			name:        "restricts the suits and excludes ranks",
+			composition: domain.Composition{Suits: []domain.Suit{domain.Hearts}, ExcludedRanks: []domain.Rank{domain.King}},
+			wantLen:     12,
+			wantFirst:   "AH",
+			wantLast:    "QH",
+		},
+		{
+			name:        "fails with an inverted range",
+			composition: domain.Composition{LowestRank: domain.Nine, HighestRank: domain.Eight},
+			wantErr:     true,
+		},
+		{
+			name:        "fails without cards",
+			composition: domain.Composition{LowestRank: domain.Two, HighestRank: domain.Two, ExcludedRanks: []domain.Rank{domain.Two}},
+			wantErr:     true,
+		},
+		{
+			name:        "fails with too many decks",
+			composition: domain.Composition{Decks: domain.MaxShoeDecks + 1},
+			wantErr:     true,
+		},
+		{
+			name:        "fails with negative jokers",
+			composition: domain.Composition{Jokers: -1},
+			wantErr:     true,
+		},
+		{
+			name:        "fails with too many jokers",
+			composition: domain.Composition{Jokers: domain.MaxJokers + 1},
+			wantErr:     true,
+		},
+	}
+	t.Run("builds a multi-deck shoe with distinguishable cards", func(t *testing.T) {
+		got, err := domain.Composition{Decks: 6}.Cards()
+		if err != nil {
+			t.Fatalf("Composition.Cards() error = %v", err)
+		}
+		if len(got) != 6*52 {
+			t.Fatalf("Composition.Cards() len = %d, want %d", len(got), 6*52)
+		}
+		seen := make(map[domain.Card]bool)
+		for _, c := range got {
+			if seen[c] {
+				t.Fatalf("Composition.Cards() has the card %s of the deck %d twice", c, c.DeckIndex)
+			}
+			seen[c] = true
+		}
+		if got[0].DeckIndex != 1 || got[len(got)-1].DeckIndex != 6 {
+			t.Errorf("Composition.Cards() deck indexes go from %d to %d, want from 1 to 6", got[0].DeckIndex, got[len(got)-1].DeckIndex)
+		}
+	})
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.composition.Cards()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Composition.Cards() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidComposition) {
+					t.Errorf("Composition.Cards() error = %v, want %v", err, domain.ErrInvalidComposition)
+				}
+				return
+			}
+			c := codes(got)
+			if len(c) != tt.wantLen || c[0] != tt.wantFirst || c[len(c)-1] != tt.wantLast {
+				t.Errorf("Composition.Cards() = %v, want %d cards from %s to %s", c, tt.wantLen, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
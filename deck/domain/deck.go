@@ -4,72 +4,284 @@ package domain
 import (
 	"errors"
 	"fmt"
-	"math/rand"
-	"strconv"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const (
-	hearts   string = "HEARTS"
-	diamonds string = "DIAMONDS"
-	clubs    string = "CLUBS"
-	spades   string = "SPADES"
-)
-
-const (
-	ranksNumber = 13
-	aceNumber   = 1
-	jackNumber  = 11
-	queenNumber = 12
-	kingNumber  = 13
-)
-
 var (
 	// ErrDeckNotFound error returned when a deck is not found in the system.
 	ErrDeckNotFound = errors.New("deck_not_found")
+	// ErrDeckNotFair error returned when revealing the secrets of a deck that isn't provably fair.
+	ErrDeckNotFair = errors.New("deck_not_fair")
+	// ErrDeckNotRevealable error returned when revealing the secrets of a deck that can still be drawn from.
+	ErrDeckNotRevealable = errors.New("deck_not_revealable")
+	// ErrInvalidPenetration error returned when placing a cut card at a penetration outside of (0, 1).
+	ErrInvalidPenetration = errors.New("invalid_penetration")
+	// ErrCardsNotInDeck error returned when drawing specific cards that aren't in the deck.
+	ErrCardsNotInDeck = errors.New("cards_not_in_deck")
+	// ErrVersionConflict error returned when saving a deck that was modified since it was retrieved.
+	ErrVersionConflict = errors.New("version_conflict")
+	// ErrDeckExpired error returned when using a deck that expired or was deleted for being idle.
+	ErrDeckExpired = errors.New("deck_expired")
+	// ErrInvalidAmount error returned when peeking at a negative amount of cards.
+	ErrInvalidAmount = errors.New("invalid_amount")
 )
 
-func suitCode(s string) string {
-	switch s {
-	case hearts:
-		return "H"
-	case diamonds:
-		return "D"
-	case clubs:
-		return "C"
-	default:
-		return "S"
-	}
+// CardsNotInDeckError lists the cards that couldn't be drawn because they aren't in the deck.
+type CardsNotInDeckError struct {
+	Codes []string
+}
+
+func (e *CardsNotInDeckError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCardsNotInDeck, strings.Join(e.Codes, ","))
+}
+
+// Unwrap allows to match the error against ErrCardsNotInDeck.
+func (e *CardsNotInDeckError) Unwrap() error {
+	return ErrCardsNotInDeck
 }
 
 // Deck represents a french deck.
 type Deck struct {
-	UUID     string
-	Shuffled bool
-	Cards    []Card
+	UUID string `json:"uuid"`
+	// Version of the deck, incremented every time it is saved. It is 0 until the deck is saved for the first time.
+	Version  int64  `json:"version"`
+	Shuffled bool   `json:"shuffled"`
+	Cards    []Card `json:"cards"`
+	// ShuffleStrategy used to shuffle the deck. Empty if the deck isn't shuffled.
+	ShuffleStrategy ShuffleStrategy `json:"shuffle_strategy,omitempty"`
+	// Seed used to shuffle the deck. Only meaningful if the deck is shuffled with the seeded strategy.
+	Seed int64 `json:"seed,omitempty"`
+	// Fairness holds the commit-reveal data of the deck if it is provably fair.
+	Fairness *Fairness `json:"fairness,omitempty"`
+	// CutCardRemaining is the number of remaining cards at which the cut card is reached and the deck should be
+	// reshuffled. It is 0 if the deck doesn't have a cut card.
+	CutCardRemaining int `json:"cut_card_remaining,omitempty"`
+	// Visibility of the remaining cards of the deck. See EffectiveVisibility for the default.
+	Visibility Visibility `json:"visibility"`
+	// Drawn are the cards drawn from the deck or its piles that haven't been placed in a pile.
+	Drawn []Card `json:"drawn,omitempty"`
+	// Piles attached to the deck, in the order they were created.
+	Piles []*Pile `json:"piles,omitempty"`
+	// Hands of the players the deck was dealt to, in the order they were first dealt.
+	Hands []*Hand `json:"hands,omitempty"`
+	// Operations that changed the order of the deck after its creation, in the order they were applied.
+	Operations []Operation `json:"operations,omitempty"`
+	// Owner is the player that created the deck, empty if it was created without a player.
+	Owner string `json:"owner,omitempty"`
+	// Tags of the deck, sorted and without duplicates, used to find it.
+	Tags []string `json:"tags,omitempty"`
+	// CreatedAt is the time the deck was created.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the deck was last changed.
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt is the time from which the deck can't be used anymore, nil if it never expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ClosedAt is the time the deck was closed, nil if it is open. Closed decks can't be modified.
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+	// UndoDepth is the maximum number of operations that can be undone on the deck, 0 if undo is disabled.
+	UndoDepth int `json:"undo_depth,omitempty"`
+	// LastEvent is the number of the last event recorded in the history of the deck, 0 if there is none.
+	LastEvent int `json:"last_event,omitempty"`
+	// Pending are the events recorded since the deck was retrieved, in the order they happened. They are stored
+	// apart from the deck when it is saved, and then cleared.
+	Pending []Event `json:"-"`
+	// Change holds the parameters of the last change made to the deck, until it is recorded in an event. It is nil
+	// if the change can't be replayed.
+	Change *EventParams `json:"-"`
+}
+
+type deckOptions struct {
+	strategy   ShuffleStrategy
+	seed       *int64
+	seedSource SeedSource
+	fairness   *Fairness
+}
+
+// DeckOption is the interface implemented to allow options while creating a new Deck.
+type DeckOption interface {
+	apply(*deckOptions)
+}
+
+type shuffleStrategyOption ShuffleStrategy
+
+func (o shuffleStrategyOption) apply(opts *deckOptions) {
+	opts.strategy = ShuffleStrategy(o)
+}
+
+// WithShuffleStrategy option to shuffle the new deck with the given strategy. Seeds are ignored by the secure
+// strategy, as its order can't be reproduced.
+func WithShuffleStrategy(s ShuffleStrategy) DeckOption {
+	return shuffleStrategyOption(s)
+}
+
+type seedOption int64
+
+func (o seedOption) apply(opts *deckOptions) {
+	seed := int64(o)
+	opts.seed = &seed
+}
+
+// WithSeed option to shuffle the new deck with the given seed, making its order reproducible.
+func WithSeed(seed int64) DeckOption {
+	return seedOption(seed)
+}
+
+type seedSourceOption struct {
+	source SeedSource
+}
+
+func (o seedSourceOption) apply(opts *deckOptions) {
+	opts.seedSource = o.source
+}
+
+// WithSeedSource option to take the seed of the new deck from the given source, unless a seed is given.
+func WithSeedSource(src SeedSource) DeckOption {
+	return seedSourceOption{source: src}
+}
+
+type fairnessOption struct {
+	fairness *Fairness
+}
+
+func (o fairnessOption) apply(opts *deckOptions) {
+	opts.fairness = o.fairness
+}
+
+// WithFairness option to make the new deck provably fair. The deck is shuffled with the seed derived from the
+// server and client seeds, taking precedence over any other seed. Requires the seeded strategy.
+func WithFairness(f *Fairness) DeckOption {
+	return fairnessOption{fairness: f}
 }
 
 // NewDeck creates a new deck with the cards given. If the shuffled flag is true, the deck gets shuffled.
-func NewDeck(shuffled bool, cards []Card) *Deck {
-	uuid := uuid.NewString()
+// By default the deck is shuffled with the seeded strategy and a seed taken from DefaultSeedSource,
+// unless the options say otherwise.
+func NewDeck(shuffled bool, cards []Card, opts ...DeckOption) *Deck {
+	options := deckOptions{
+		strategy:   SeededShuffle,
+		seedSource: DefaultSeedSource(),
+	}
 
-	if shuffled {
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	for _, o := range opts {
+		o.apply(&options)
 	}
 
-	return &Deck{
-		UUID:     uuid,
+	d := &Deck{
+		UUID:     uuid.NewString(),
 		Shuffled: shuffled,
 		Cards:    cards,
 	}
+
+	if !shuffled {
+		return d
+	}
+
+	d.ShuffleStrategy = options.strategy
+
+	if options.strategy == SecureShuffle {
+		SecureShuffler{}.Shuffle(d.Cards)
+
+		return d
+	}
+
+	switch {
+	case options.fairness != nil:
+		d.Fairness = options.fairness
+		d.Seed = FairSeed(options.fairness.ServerSeed, options.fairness.ClientSeed)
+	case options.seed != nil:
+		d.Seed = *options.seed
+	default:
+		d.Seed = options.seedSource.Int63()
+	}
+
+	SeededShuffler{Seed: d.Seed}.Shuffle(d.Cards)
+
+	return d
+}
+
+// Clone returns a deep copy of the deck, so it can be modified without affecting the original one.
+func (d *Deck) Clone() *Deck {
+	c := *d
+	c.Cards = append([]Card(nil), d.Cards...)
+	c.Visibility = d.Visibility.clone()
+
+	if d.Fairness != nil {
+		f := *d.Fairness
+		c.Fairness = &f
+	}
+
+	if d.ExpiresAt != nil {
+		e := *d.ExpiresAt
+		c.ExpiresAt = &e
+	}
+
+	if d.ClosedAt != nil {
+		closedAt := *d.ClosedAt
+		c.ClosedAt = &closedAt
+	}
+
+	c.Tags = append([]string(nil), d.Tags...)
+	c.Drawn = append([]Card(nil), d.Drawn...)
+	c.Piles = nil
+
+	for _, p := range d.Piles {
+		c.Piles = append(c.Piles, &Pile{Name: p.Name, Cards: append([]Card(nil), p.Cards...), Visibility: p.Visibility.clone()})
+	}
+
+	c.Hands = nil
+
+	for _, h := range d.Hands {
+		c.Hands = append(c.Hands, &Hand{Player: h.Player, Cards: append([]Card(nil), h.Cards...), Visibility: h.Visibility.clone()})
+	}
+
+	c.Operations = append([]Operation(nil), d.Operations...)
+	// The events are immutable, so their states are shared between clones.
+	c.Pending = append([]Event(nil), d.Pending...)
+
+	return &c
+}
+
+// Revealable returns whether the secrets of the deck can be revealed, which happens once the deck is exhausted or
+// closed, as no more cards can be drawn from it.
+func (d *Deck) Revealable() bool {
+	return len(d.Cards) == 0 || d.Closed()
+}
+
+// PlaceCutCard places the cut card after the given fraction of the cards, so the deck reports it should be
+// reshuffled once that fraction of the cards is drawn. Returns an error if the penetration is not between 0 and 1.
+func (d *Deck) PlaceCutCard(penetration float64) error {
+	if penetration <= 0 || penetration >= 1 {
+		return ErrInvalidPenetration
+	}
+
+	// The cut card always leaves at least one card on each side.
+	position := int(math.Round(float64(len(d.Cards)) * penetration))
+
+	if position > len(d.Cards)-1 {
+		position = len(d.Cards) - 1
+	}
+
+	if position < 1 {
+		position = 1
+	}
+
+	d.CutCardRemaining = len(d.Cards) - position
+
+	return nil
+}
+
+// NeedsReshuffle returns whether the cut card has been reached.
+func (d *Deck) NeedsReshuffle() bool {
+	return d.CutCardRemaining > 0 && len(d.Cards) <= d.CutCardRemaining
 }
 
 // Draw draws the amount of cards given as parameter from the top of the deck.
 // If the amount given is more than the number of cards in the deck, draws all the cards available.
+// The cards drawn are kept as drawn cards of the deck, so they can be placed in its piles later.
 func (d *Deck) Draw(amount int) []Card {
 	if amount > len(d.Cards) {
 		amount = len(d.Cards)
@@ -77,101 +289,92 @@ func (d *Deck) Draw(amount int) []Card {
 
 	drawnCards := d.Cards[:amount]
 	d.Cards = d.Cards[amount:]
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.Change = &EventParams{Cards: append([]Card(nil), drawnCards...)}
 
 	return drawnCards
 }
 
-// Card represents a card inside a french deck.
-type Card struct {
-	Value string `json:"value"`
-	Suit  string `json:"suit"`
-	Code  string `json:"code"`
-}
+// DrawFrom draws the amount of cards given from the given position of the deck. Drawn from the top, the cards are
+// drawn as with Draw. Drawn from the bottom, the bottom card is the first one drawn. Drawn from random positions,
+// the shuffler picks the cards while the rest of the deck keeps its order.
+// If the amount given is more than the number of cards in the deck, draws all the cards available.
+// Returns an error if the position is unknown.
+func (d *Deck) DrawFrom(position Position, amount int, s Shuffler) ([]Card, error) {
+	if _, err := ParsePosition(string(position)); err != nil {
+		return nil, err
+	}
 
-// CompleteDeckCards returns a complete set of french deck cards sorted.
-func CompleteDeckCards() []Card {
-	suits := []string{clubs, diamonds, hearts, spades}
-	cards := make([]Card, ranksNumber*len(suits))
+	if amount > len(d.Cards) {
+		amount = len(d.Cards)
+	}
 
-	for i, s := range suits {
-		for j := 1; j <= ranksNumber; j++ {
-			cards[i*ranksNumber+j-1] = Card{
-				Value: numberToValue(j),
-				Suit:  s,
-				Code:  fmt.Sprintf("%s%s", numberToCodePart(j), suitCode(s)),
-			}
+	var drawnCards []Card
+
+	switch position {
+	case PositionTop:
+		return d.Draw(amount), nil
+	case PositionBottom:
+		drawnCards = make([]Card, amount)
+
+		for i := range drawnCards {
+			drawnCards[i] = d.Cards[len(d.Cards)-1-i]
 		}
+
+		d.Cards = d.Cards[:len(d.Cards)-amount]
+	case PositionRandom:
+		picked := append([]Card(nil), d.Cards...)
+		s.Shuffle(picked)
+		d.Cards, drawnCards, _ = takeCards(d.Cards, picked[:amount])
 	}
 
-	return cards
-}
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.Change = &EventParams{Cards: append([]Card(nil), drawnCards...)}
 
-// FromCode returns the card represented by the code given as a parameter.
-func FromCode(code string) Card {
-	codeSlice := strings.Split(code, "")
+	return drawnCards, nil
+}
 
-	var (
-		value string
-		suit  string
-	)
+// DrawCards draws the given cards from wherever they are in the deck, while the rest of the deck keeps its order.
+// A card without deck index matches the same card of any deck of a shoe. Returns an error listing the cards that
+// aren't in the deck, in which case the deck isn't modified.
+func (d *Deck) DrawCards(cards []Card) ([]Card, error) {
+	remaining, drawnCards, missing := takeCards(d.Cards, cards)
 
-	switch codeSlice[0] {
-	case "A":
-		value = "ACE"
-	case "J":
-		value = "JACK"
-	case "Q":
-		value = "QUEEN"
-	case "K":
-		value = "KING"
-	default:
-		value = codeSlice[0]
+	if len(missing) > 0 {
+		return nil, &CardsNotInDeckError{Codes: missing}
 	}
 
-	switch codeSlice[1] {
-	case "H":
-		suit = hearts
-	case "D":
-		suit = diamonds
-	case "C":
-		suit = clubs
-	default:
-		suit = spades
-	}
+	d.Cards = remaining
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.Change = &EventParams{Cards: append([]Card(nil), drawnCards...)}
 
-	return Card{
-		Value: value,
-		Suit:  suit,
-		Code:  code,
-	}
+	return drawnCards, nil
 }
 
-func numberToValue(n int) string {
-	switch n {
-	case aceNumber:
-		return "ACE"
-	case jackNumber:
-		return "JACK"
-	case queenNumber:
-		return "QUEEN"
-	case kingNumber:
-		return "KING"
-	default:
-		return strconv.Itoa(n)
+// Peek returns the amount of cards given from the top or the bottom of the deck without drawing them. Peeked from
+// the bottom, the bottom card comes first. If the amount given is more than the number of cards in the deck, returns
+// all the cards available. Returns an error if the amount is negative or if the position isn't the top or the bottom.
+func (d *Deck) Peek(position Position, amount int) ([]Card, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: must be greater or equal to 0", ErrInvalidAmount)
 	}
-}
 
-func numberToCodePart(n int) string {
-	switch n {
-	case aceNumber:
-		return "A"
-	case jackNumber:
-		return "J"
-	case queenNumber:
-		return "Q"
-	case kingNumber:
-		return "K"
+	if amount > len(d.Cards) {
+		amount = len(d.Cards)
+	}
+
+	switch position {
+	case PositionTop:
+		return append([]Card(nil), d.Cards[:amount]...), nil
+	case PositionBottom:
+		peeked := make([]Card, amount)
+
+		for i := range peeked {
+			peeked[i] = d.Cards[len(d.Cards)-1-i]
+		}
+
+		return peeked, nil
 	default:
-		return strconv.Itoa(n)
+		return nil, fmt.Errorf("%w: can't peek from %q", ErrInvalidPosition, position)
 	}
 }
@@ -1,6 +1,8 @@
 package domain_test
 
 import (
+	"errors"
+	"math/rand"
 	"reflect"
 	"testing"
 
@@ -9,7 +11,7 @@ import (
 
 func TestNewDeck(t *testing.T) {
 	t.Run("works correctly with shuffling", func(t *testing.T) {
-		c := []domain.Card{{Value: "3", Suit: "HEARTS", Code: "3H"}, {Value: "4", Suit: "SPADES", Code: "4S"}}
+		c := []domain.Card{{Rank: domain.Three, Suit: domain.Hearts}, {Rank: domain.Four, Suit: domain.Spades}}
 		got := domain.NewDeck(true, c)
 		if len(got.Cards) != len(c) {
 			t.Errorf("New deck length = %d, want %d", len(got.Cards), len(c))
@@ -22,7 +24,7 @@ func TestNewDeck(t *testing.T) {
 		}
 	})
 	t.Run("works correctly without shuffling", func(t *testing.T) {
-		c := []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}}
+		c := []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}}
 		want := &domain.Deck{
 			UUID:     "some-uuid",
 			Shuffled: false,
@@ -39,6 +41,26 @@ func TestNewDeck(t *testing.T) {
 	})
 }
 
+func TestNewDeck_WithSeed(t *testing.T) {
+	t.Run("shuffles in the same order with the same seed", func(t *testing.T) {
+		first := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithSeed(42))
+		second := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithSeed(42))
+		if first.Seed != 42 {
+			t.Errorf("New deck seed = %d, want 42", first.Seed)
+		}
+		if !reflect.DeepEqual(first.Cards, second.Cards) {
+			t.Errorf("New decks with the same seed = %v and %v, want the same order", first.Cards, second.Cards)
+		}
+	})
+	t.Run("takes the seed from the seed source", func(t *testing.T) {
+		got := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithSeedSource(rand.NewSource(1)))
+		want := rand.NewSource(1).Int63()
+		if got.Seed != want {
+			t.Errorf("New deck seed = %d, want %d", got.Seed, want)
+		}
+	})
+}
+
 func TestCompleteDeckCards(t *testing.T) {
 	t.Run("generates a complete deck correctly sorted", func(t *testing.T) {
 		got := domain.CompleteDeckCards()
@@ -46,7 +68,7 @@ func TestCompleteDeckCards(t *testing.T) {
 			t.Errorf("Complete deck len = %d, want 52", len(got))
 		}
 
-		if got[13*0].Code != "AC" && got[13*1].Code != "AD" && got[13*2].Code != "AH" && got[13*3].Code != "AS" {
+		if got[13*0].Code() != "AC" && got[13*1].Code() != "AD" && got[13*2].Code() != "AH" && got[13*3].Code() != "AS" {
 			t.Error("The deck wasn't sorted")
 		}
 	})
@@ -54,71 +76,147 @@ func TestCompleteDeckCards(t *testing.T) {
 
 func TestFromCode(t *testing.T) {
 	tests := []struct {
-		name string
-		code string
-		want domain.Card
+		name    string
+		code    string
+		want    domain.Card
+		wantErr bool
 	}{
 		{
 			name: "works with numbered cards",
 			code: "4D",
 			want: domain.Card{
-				Value: "4",
-				Suit:  "DIAMONDS",
-				Code:  "4D",
+				Rank: domain.Four,
+				Suit: domain.Diamonds,
+			},
+		},
+		{
+			name: "works with ten cards",
+			code: "10H",
+			want: domain.Card{
+				Rank: domain.Ten,
+				Suit: domain.Hearts,
+			},
+		},
+		{
+			name: "works with ten cards using the T rank",
+			code: "TC",
+			want: domain.Card{
+				Rank: domain.Ten,
+				Suit: domain.Clubs,
 			},
 		},
 		{
 			name: "works for Ace cards",
 			code: "AS",
 			want: domain.Card{
-				Value: "ACE",
-				Suit:  "SPADES",
-				Code:  "AS",
+				Rank: domain.Ace,
+				Suit: domain.Spades,
 			},
 		},
 		{
 			name: "works for Jack cards",
 			code: "JH",
 			want: domain.Card{
-				Value: "JACK",
-				Suit:  "HEARTS",
-				Code:  "JH",
+				Rank: domain.Jack,
+				Suit: domain.Hearts,
 			},
 		},
 		{
 			name: "works for Queen cards",
 			code: "QC",
 			want: domain.Card{
-				Value: "QUEEN",
-				Suit:  "CLUBS",
-				Code:  "QC",
+				Rank: domain.Queen,
+				Suit: domain.Clubs,
 			},
 		},
 		{
 			name: "works for King cards",
 			code: "KS",
 			want: domain.Card{
-				Value: "KING",
-				Suit:  "SPADES",
-				Code:  "KS",
+				Rank: domain.King,
+				Suit: domain.Spades,
 			},
 		},
+		{
+			name: "works for jokers",
+			code: "X",
+			want: domain.NewJoker(),
+		},
+		{
+			name:    "fails for jokers with a suit",
+			code:    "XH",
+			wantErr: true,
+		},
+		{
+			name:    "fails with an empty code",
+			code:    "",
+			wantErr: true,
+		},
+		{
+			name:    "fails with an unknown rank",
+			code:    "1H",
+			wantErr: true,
+		},
+		{
+			name:    "fails with an unknown suit",
+			code:    "AZ",
+			wantErr: true,
+		},
+		{
+			name:    "fails without a suit",
+			code:    "K",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			if got := domain.FromCode(tt.code); !reflect.DeepEqual(got, tt.want) {
+			got, err := domain.FromCode(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && !errors.Is(err, domain.ErrInvalidCardCode) {
+				t.Errorf("FromCode() error = %v, want %v", err, domain.ErrInvalidCardCode)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("FromCode() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestFromCodes(t *testing.T) {
+	t.Run("parses every code keeping the order", func(t *testing.T) {
+		got, err := domain.FromCodes([]string{"AS", "10D"})
+		if err != nil {
+			t.Fatalf("FromCodes() error = %v", err)
+		}
+		want := []domain.Card{{Rank: domain.Ace, Suit: domain.Spades}, {Rank: domain.Ten, Suit: domain.Diamonds}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("FromCodes() = %v, want %v", got, want)
+		}
+	})
+	t.Run("reports every invalid code with its position", func(t *testing.T) {
+		_, err := domain.FromCodes([]string{"AS", "", "KD", "ZZ"})
+		var invalidErr *domain.InvalidCardsError
+		if !errors.As(err, &invalidErr) {
+			t.Fatalf("FromCodes() error = %v, want an *InvalidCardsError", err)
+		}
+		if len(invalidErr.Errors) != 2 {
+			t.Fatalf("FromCodes() errors = %d, want 2", len(invalidErr.Errors))
+		}
+		if invalidErr.Errors[0].Position != 1 || invalidErr.Errors[1].Position != 3 {
+			t.Errorf("FromCodes() positions = %d and %d, want 1 and 3", invalidErr.Errors[0].Position, invalidErr.Errors[1].Position)
+		}
+	})
+}
+
 func testDeck() *domain.Deck {
 	return &domain.Deck{
 		UUID:     "test-uuid",
 		Shuffled: true,
-		Cards:    []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}},
+		Cards:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
 	}
 }
 
@@ -134,33 +232,43 @@ func TestDeck_Draw(t *testing.T) {
 			name:   "draws 1 card correctly",
 			deck:   testDeck(),
 			amount: 1,
-			want:   []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}},
+			want:   []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}},
 			wantDeck: &domain.Deck{
 				UUID:     "test-uuid",
 				Shuffled: true,
-				Cards:    []domain.Card{{Value: "5", Suit: "SPADES", Code: "5S"}},
+				Cards:    []domain.Card{{Rank: domain.Five, Suit: domain.Spades}},
+				Drawn:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}},
+				Change:   &domain.EventParams{Cards: []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}}},
 			},
 		},
 		{
 			name:   "draws all cards correctly",
 			deck:   testDeck(),
 			amount: 2,
-			want:   []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}},
+			want:   []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
 			wantDeck: &domain.Deck{
 				UUID:     "test-uuid",
 				Shuffled: true,
 				Cards:    []domain.Card{},
+				Drawn:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+				Change: &domain.EventParams{
+					Cards: []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+				},
 			},
 		},
 		{
 			name:   "drawing more than all cards",
 			deck:   testDeck(),
 			amount: 3,
-			want:   []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}},
+			want:   []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
 			wantDeck: &domain.Deck{
 				UUID:     "test-uuid",
 				Shuffled: true,
 				Cards:    []domain.Card{},
+				Drawn:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+				Change: &domain.EventParams{
+					Cards: []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+				},
 			},
 		},
 	}
@@ -176,3 +284,148 @@ func TestDeck_Draw(t *testing.T) {
 		})
 	}
 }
+
+func TestDeck_DrawFrom(t *testing.T) {
+	t.Run("draws from the bottom", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		got, err := d.DrawFrom(domain.PositionBottom, 2, domain.SeededShuffler{})
+		want := []domain.Card{{Rank: domain.King, Suit: domain.Spades}, {Rank: domain.Queen, Suit: domain.Spades}}
+		if err != nil {
+			t.Fatalf("Deck.DrawFrom() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Deck.DrawFrom() = %v, want %v", got, want)
+		}
+		if !reflect.DeepEqual(d.Cards, domain.CompleteDeckCards()[:50]) || !reflect.DeepEqual(d.Drawn, want) {
+			t.Errorf("Deck after DrawFrom() = %v, want the bottom cards moved to the drawn cards", d)
+		}
+	})
+	t.Run("draws from random positions keeping the order of the rest", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		got, err := d.DrawFrom(domain.PositionRandom, 5, domain.SeededShuffler{Seed: 9})
+		if err != nil {
+			t.Fatalf("Deck.DrawFrom() error = %v", err)
+		}
+		if len(got) != 5 || len(d.Cards) != 47 {
+			t.Fatalf("Deck.DrawFrom() = %v, leaving %d cards, want 5 cards leaving 47", got, len(d.Cards))
+		}
+		var want []domain.Card
+		for _, c := range domain.CompleteDeckCards() {
+			if !containsCard(got, c) {
+				want = append(want, c)
+			}
+		}
+		if !reflect.DeepEqual(d.Cards, want) {
+			t.Errorf("Deck after DrawFrom() = %v, want %v", d.Cards, want)
+		}
+	})
+	t.Run("returns an error if the position is unknown", func(t *testing.T) {
+		if _, err := testDeck().DrawFrom("middle", 1, domain.SeededShuffler{}); !errors.Is(err, domain.ErrInvalidPosition) {
+			t.Errorf("Deck.DrawFrom() error = %v, want %v", err, domain.ErrInvalidPosition)
+		}
+	})
+}
+
+func TestDeck_DrawCards(t *testing.T) {
+	t.Run("draws the given cards", func(t *testing.T) {
+		d := testDeck()
+		got, err := d.DrawCards([]domain.Card{{Rank: domain.Five, Suit: domain.Spades}})
+		want := []domain.Card{{Rank: domain.Five, Suit: domain.Spades}}
+		if err != nil {
+			t.Fatalf("Deck.DrawCards() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(d.Drawn, want) {
+			t.Errorf("Deck.DrawCards() = %v, drawn %v, want %v", got, d.Drawn, want)
+		}
+		if wantCards := []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}}; !reflect.DeepEqual(d.Cards, wantCards) {
+			t.Errorf("Deck after DrawCards() = %v, want %v", d.Cards, wantCards)
+		}
+	})
+	t.Run("returns an error listing the cards not in the deck", func(t *testing.T) {
+		d := testDeck()
+		_, err := d.DrawCards([]domain.Card{{Rank: domain.Five, Suit: domain.Spades}, {Rank: domain.Ace, Suit: domain.Spades}})
+		var notInDeckErr *domain.CardsNotInDeckError
+		if !errors.As(err, &notInDeckErr) || !reflect.DeepEqual(notInDeckErr.Codes, []string{"AS"}) {
+			t.Fatalf("Deck.DrawCards() error = %v, want the missing AS", err)
+		}
+		if !reflect.DeepEqual(d, testDeck()) {
+			t.Errorf("Deck after a failed DrawCards() = %v, want it unmodified", d)
+		}
+	})
+}
+
+func TestDeck_Peek(t *testing.T) {
+	tests := []struct {
+		name     string
+		position domain.Position
+		amount   int
+		want     []domain.Card
+		wantErr  error
+	}{
+		{
+			name:     "peeks at the top cards",
+			position: domain.PositionTop,
+			amount:   1,
+			want:     []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}},
+		},
+		{
+			name:     "peeks at the bottom cards with the bottom card first",
+			position: domain.PositionBottom,
+			amount:   5,
+			want:     []domain.Card{{Rank: domain.Five, Suit: domain.Spades}, {Rank: domain.Four, Suit: domain.Hearts}},
+		},
+		{
+			name:     "returns an error peeking at random positions",
+			position: domain.PositionRandom,
+			amount:   1,
+			wantErr:  domain.ErrInvalidPosition,
+		},
+		{
+			name:     "returns an error peeking at a negative amount",
+			position: domain.PositionTop,
+			amount:   -1,
+			wantErr:  domain.ErrInvalidAmount,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDeck()
+			got, err := d.Peek(tt.position, tt.amount)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Deck.Peek() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Deck.Peek() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(d, testDeck()) {
+				t.Errorf("Deck after Peek() = %v, want it unmodified", d)
+			}
+		})
+	}
+}
+
+func TestDeck_PlaceCutCard(t *testing.T) {
+	t.Run("reports the reshuffle once the cut card is reached", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.PlaceCutCard(0.75); err != nil {
+			t.Fatalf("Deck.PlaceCutCard() error = %v", err)
+		}
+		d.Draw(38)
+		if d.NeedsReshuffle() {
+			t.Error("Deck.NeedsReshuffle() = true before reaching the cut card")
+		}
+		d.Draw(1)
+		if !d.NeedsReshuffle() {
+			t.Error("Deck.NeedsReshuffle() = false after reaching the cut card")
+		}
+	})
+	t.Run("fails with an invalid penetration", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		for _, p := range []float64{0, 1, -0.5, 1.5} {
+			if err := d.PlaceCutCard(p); !errors.Is(err, domain.ErrInvalidPenetration) {
+				t.Errorf("Deck.PlaceCutCard(%v) error = %v, want %v", p, err, domain.ErrInvalidPenetration)
+			}
+		}
+	})
+}
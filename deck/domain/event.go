@@ -0,0 +1,221 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// checkpointEvery is how often an event keeps the state of the deck right after it, bounding the events replayed to
+// rebuild the state of the deck at any event.
+const checkpointEvery = 32
+
+var (
+	// ErrEventNotFound error returned when a deck doesn't have an event with the given number.
+	ErrEventNotFound = errors.New("event_not_found")
+)
+
+// EventType names what happened to a deck.
+type EventType string
+
+// Types of the events recorded in the history of a deck.
+const (
+	CreatedEvent           EventType = "created"
+	DrawnEvent             EventType = "drawn"
+	DealtEvent             EventType = "dealt"
+	ReturnedEvent          EventType = "returned"
+	ReshuffledEvent        EventType = "reshuffled"
+	ResetEvent             EventType = "reset"
+	CutEvent               EventType = "cut"
+	RiffledEvent           EventType = "riffled"
+	OverhandedEvent        EventType = "overhanded"
+	AddedToPileEvent       EventType = "added_to_pile"
+	DrawnFromPileEvent     EventType = "drawn_from_pile"
+	PileShuffledEvent      EventType = "pile_shuffled"
+	VisibilityChangedEvent EventType = "visibility_changed"
+	UndoneEvent            EventType = "undone"
+	ClosedEvent            EventType = "closed"
+)
+
+// Event records something that happened to a deck. Events are immutable once recorded.
+type Event struct {
+	// Number of the event in the history of the deck, starting from 1.
+	Number int       `json:"number"`
+	Type   EventType `json:"type"`
+	At     time.Time `json:"at"`
+	// RequestID of the request that caused the event, if any.
+	RequestID string `json:"request_id,omitempty"`
+	// Player that made the request, if any.
+	Player string `json:"player,omitempty"`
+	// RevertedTo is the number of the event whose state the deck was reverted to, only set for undone events.
+	RevertedTo int `json:"reverted_to,omitempty"`
+	// Params of the change recorded by the event, nil if it can't be replayed.
+	Params *EventParams `json:"params,omitempty"`
+	// State of the deck right after the event, without its pending events. It is only kept as a checkpoint by the
+	// creation, by the changes that can't be replayed and periodically, so the states in between are rebuilt
+	// replaying the events after a checkpoint. Its version is the one the deck gets once saved with the event.
+	State *Deck `json:"state,omitempty"`
+}
+
+// EventParams are the parameters of the change recorded by an event, enough to replay it on the state of the deck
+// right before the event. Only the parameters of the type of the event are set.
+type EventParams struct {
+	// Cards drawn, returned or added to a pile, in order.
+	Cards []Card `json:"cards,omitempty"`
+	// Amount of cards drawn from a pile or dealt to each player.
+	Amount int `json:"amount,omitempty"`
+	// Position the cards were returned to.
+	Position Position `json:"position,omitempty"`
+	// Seed of the shuffler placing the returned cards at random positions or shuffling a pile.
+	Seed int64 `json:"seed,omitempty"`
+	// Pile the cards were added to, drawn from or shuffled, or whose visibility changed.
+	Pile string `json:"pile,omitempty"`
+	// Hand whose visibility changed.
+	Hand string `json:"hand,omitempty"`
+	// Players dealt to, in order.
+	Players []string `json:"players,omitempty"`
+	// Operation that changed the order of the deck, set by cuts and shuffles.
+	Operation *Operation `json:"operation,omitempty"`
+	// Visibility set.
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
+
+// History is the list of the events of a deck, in the order they happened.
+type History []Event
+
+// Record records an event of the given type in the pending events of the deck, with the parameters of the last
+// change made to the deck, or with the state of the deck if the change can't be replayed.
+func (d *Deck) Record(eventType EventType, at time.Time, requestID, player string) {
+	params := d.Change
+	d.Change = nil
+	d.LastEvent++
+
+	e := Event{
+		Number:    d.LastEvent,
+		Type:      eventType,
+		At:        at,
+		RequestID: requestID,
+		Player:    player,
+		Params:    params,
+	}
+
+	if eventType == CreatedEvent || params == nil || e.Number%checkpointEvery == 0 {
+		e.State = d.checkpoint(at)
+	}
+
+	d.Pending = append(d.Pending, e)
+}
+
+// checkpoint returns a copy of the deck without its pending events, as it is once saved at the given time.
+func (d *Deck) checkpoint(at time.Time) *Deck {
+	pending := d.Pending
+	d.Pending = nil
+	s := d.Clone()
+	d.Pending = pending
+
+	s.Version++
+	s.UpdatedAt = at
+
+	return s
+}
+
+// StateAt rebuilds the state of the deck right after the event with the given number, replaying the events since
+// the last checkpoint before it. Returns an error if the history doesn't have the event or if it can't be replayed.
+func (h History) StateAt(number int) (*Deck, error) {
+	if number < 1 || number > len(h) {
+		return nil, fmt.Errorf("%w: %d", ErrEventNotFound, number)
+	}
+
+	start := number - 1
+
+	for start > 0 && h[start].State == nil {
+		start--
+	}
+
+	if h[start].State == nil {
+		return nil, fmt.Errorf("the event %d doesn't have a state to replay from", h[start].Number)
+	}
+
+	d := h[start].State.Clone()
+
+	for _, e := range h[start+1 : number] {
+		if err := h.replay(d, e); err != nil {
+			return nil, fmt.Errorf("replaying the event %d failed: %w", e.Number, err)
+		}
+	}
+
+	return d, nil
+}
+
+// replay changes the deck as the event did, so it ends in its state right after the event.
+func (h History) replay(d *Deck, e Event) error {
+	version := d.Version
+
+	if err := h.apply(d, e); err != nil {
+		return err
+	}
+
+	d.Version, d.LastEvent, d.UpdatedAt, d.Change = version+1, e.Number, e.At, nil
+
+	return nil
+}
+
+// apply makes again the change recorded by the event.
+func (h History) apply(d *Deck, e Event) error {
+	p := e.Params
+
+	if p == nil {
+		return errors.New("the event doesn't have parameters")
+	}
+
+	switch e.Type {
+	case DrawnEvent:
+		_, err := d.DrawCards(p.Cards)
+		return err
+	case DealtEvent:
+		return d.Deal(p.Players, p.Amount)
+	case ReturnedEvent:
+		return d.Return(p.Cards, p.Position, SeededShuffler{Seed: p.Seed})
+	case ReshuffledEvent, ResetEvent:
+		if p.Operation == nil || p.Operation.Strategy != SeededShuffle {
+			return errors.New("the shuffle isn't seeded")
+		}
+
+		if e.Type == ResetEvent {
+			d.Reset(SeededShuffler{Seed: p.Operation.Seed})
+		} else {
+			d.Reshuffle(SeededShuffler{Seed: p.Operation.Seed})
+		}
+
+		return nil
+	case CutEvent, RiffledEvent, OverhandedEvent:
+		if p.Operation == nil {
+			return errors.New("the event doesn't have an operation")
+		}
+
+		return d.replayOperation(*p.Operation)
+	case AddedToPileEvent:
+		return d.AddToPile(p.Pile, p.Cards)
+	case DrawnFromPileEvent:
+		_, err := d.DrawFromPile(p.Pile, p.Amount)
+		return err
+	case PileShuffledEvent:
+		return d.ShufflePile(p.Pile, SeededShuffler{Seed: p.Seed})
+	case VisibilityChangedEvent:
+		return d.replayVisibility(p)
+	case UndoneEvent:
+		state, err := h.StateAt(e.RevertedTo)
+
+		if err != nil {
+			return err
+		}
+
+		*d = *state
+
+		return nil
+	case ClosedEvent:
+		return d.Close(e.At)
+	default:
+		return fmt.Errorf("events of type %q can't be replayed", e.Type)
+	}
+}
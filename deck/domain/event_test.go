@@ -0,0 +1,165 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_Record(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	d.Record(domain.CreatedEvent, at, "request-1", "")
+	d.Draw(2)
+	d.Record(domain.DrawnEvent, at.Add(time.Second), "request-2", "alice")
+	d.Draw(3)
+
+	if len(d.Pending) != 2 || d.LastEvent != 2 {
+		t.Fatalf("Deck.Record() recorded %d events up to %d, want 2", len(d.Pending), d.LastEvent)
+	}
+	if created := d.Pending[0]; created.State == nil || created.State.Pending != nil {
+		t.Errorf("Deck.Record() created event state = %v, want the state without pending events", created.State)
+	}
+	e := d.Pending[1]
+	if e.Number != 2 || e.Type != domain.DrawnEvent || e.RequestID != "request-2" || e.Player != "alice" || !e.At.Equal(at.Add(time.Second)) {
+		t.Errorf("Deck.Record() event = %+v, want the second drawn event of alice", e)
+	}
+	if e.State != nil || e.Params == nil || !reflect.DeepEqual(e.Params.Cards, domain.CompleteDeckCards()[:2]) {
+		t.Errorf("Deck.Record() event = %+v, want the drawn cards without the state", e)
+	}
+
+	t.Run("keeps the state of changes that can't be replayed", func(t *testing.T) {
+		c := d.Clone()
+		c.Reshuffle(domain.SecureShuffler{})
+		c.Record(domain.ReshuffledEvent, at, "", "")
+		if e := c.Pending[len(c.Pending)-1]; e.Params != nil || e.State == nil {
+			t.Errorf("Deck.Record() event = %+v, want the state without parameters", e)
+		}
+	})
+	t.Run("keeps the state periodically", func(t *testing.T) {
+		c := d.Clone()
+		for c.LastEvent < 32 {
+			c.Draw(1)
+			c.Record(domain.DrawnEvent, at, "", "")
+		}
+		if e := c.Pending[31]; e.Params == nil || e.State == nil || !reflect.DeepEqual(e.State.Cards, c.Cards) {
+			t.Errorf("Deck.Record() event = %+v, want the parameters along with the state", e)
+		}
+	})
+}
+
+func TestHistory_StateAt(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	d.Record(domain.CreatedEvent, at, "", "")
+	d.Draw(2)
+	d.Record(domain.DrawnEvent, at, "", "")
+
+	t.Run("rebuilds the state right after the event", func(t *testing.T) {
+		got, err := domain.History(d.Pending).StateAt(2)
+		if err != nil {
+			t.Fatalf("History.StateAt() error = %v", err)
+		}
+		if !reflect.DeepEqual(got.Cards, domain.CompleteDeckCards()[2:]) || len(got.Drawn) != 2 {
+			t.Errorf("History.StateAt() = %v, want the deck after drawing 2 cards", got)
+		}
+		got.Draw(1)
+		if again, _ := domain.History(d.Pending).StateAt(2); len(again.Cards) != 50 {
+			t.Error("History.StateAt() returned a state sharing the recorded one")
+		}
+	})
+	t.Run("returns an error if the event doesn't exist", func(t *testing.T) {
+		for _, number := range []int{0, 3} {
+			if _, err := domain.History(d.Pending).StateAt(number); !errors.Is(err, domain.ErrEventNotFound) {
+				t.Errorf("History.StateAt(%d) error = %v, want %v", number, err, domain.ErrEventNotFound)
+			}
+		}
+	})
+	t.Run("replays every kind of event to the state the deck had", func(t *testing.T) {
+		d := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithSeed(7))
+		if err := d.SetUndoDepth(10); err != nil {
+			t.Fatalf("Deck.SetUndoDepth() error = %v", err)
+		}
+		faceUp := domain.Visibility{Mode: domain.FaceUp}
+		seeded := domain.SeededShuffler{Seed: 42}
+		steps := []struct {
+			eventType domain.EventType
+			change    func(d *domain.Deck) error
+		}{
+			{domain.DrawnEvent, func(d *domain.Deck) error {
+				_, err := d.DrawFrom(domain.PositionRandom, 3, domain.SecureShuffler{})
+				return err
+			}},
+			{domain.AddedToPileEvent, func(d *domain.Deck) error { return d.AddToPile("discard", d.Drawn[:2]) }},
+			{domain.DrawnFromPileEvent, func(d *domain.Deck) error {
+				_, err := d.DrawFromPile("discard", 1)
+				return err
+			}},
+			{domain.PileShuffledEvent, func(d *domain.Deck) error { return d.ShufflePile("discard", seeded) }},
+			{domain.ReturnedEvent, func(d *domain.Deck) error {
+				return d.Return(d.Drawn[:1], domain.PositionRandom, seeded)
+			}},
+			{domain.DealtEvent, func(d *domain.Deck) error { return d.Deal([]string{"alice", "bob"}, 2) }},
+			{domain.CutEvent, func(d *domain.Deck) error { return d.Cut(5) }},
+			{domain.RiffledEvent, func(d *domain.Deck) error { return d.Riffle(2, 3) }},
+			{domain.OverhandedEvent, func(d *domain.Deck) error { return d.Overhand(1, 4) }},
+			{domain.ReshuffledEvent, func(d *domain.Deck) error {
+				d.Reshuffle(domain.SecureShuffler{})
+				return nil
+			}},
+			{domain.VisibilityChangedEvent, func(d *domain.Deck) error { return d.SetHandVisibility("alice", faceUp) }},
+			{domain.VisibilityChangedEvent, func(d *domain.Deck) error { return d.SetPileVisibility("discard", faceUp) }},
+			{domain.ResetEvent, func(d *domain.Deck) error {
+				d.Reset(seeded)
+				return nil
+			}},
+		}
+
+		var states []*domain.Deck
+
+		save := func(at time.Time) {
+			d.Version++
+			d.UpdatedAt = at
+			state := d.Clone()
+			state.Pending = nil
+			states = append(states, state)
+		}
+
+		d.Record(domain.CreatedEvent, at, "", "")
+		save(at)
+
+		for round := 0; round < 3; round++ {
+			for _, step := range steps {
+				at = at.Add(time.Second)
+				if err := step.change(d); err != nil {
+					t.Fatalf("changing the deck with a %s event failed: %v", step.eventType, err)
+				}
+				d.Record(step.eventType, at, "", "")
+				save(at)
+			}
+
+			at = at.Add(time.Second)
+			if err := d.Undo(d.Pending, 2, at, "", ""); err != nil {
+				t.Fatalf("Deck.Undo() error = %v", err)
+			}
+			save(at)
+		}
+
+		d.Close(at)
+		d.Record(domain.ClosedEvent, at, "", "")
+		save(at)
+
+		for i, want := range states {
+			got, err := domain.History(d.Pending).StateAt(i + 1)
+			if err != nil {
+				t.Fatalf("History.StateAt(%d) error = %v", i+1, err)
+			}
+			if !reflect.DeepEqual(got.Clone(), want) {
+				t.Errorf("History.StateAt(%d) = %+v, want %+v", i+1, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidTTL error returned when setting a time to live that isn't positive.
+	ErrInvalidTTL = errors.New("invalid_ttl")
+)
+
+// SetTTL makes the deck expire once the given time to live passes since its creation.
+// Returns an error if the time to live isn't positive.
+func (d *Deck) SetTTL(ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+
+	expiresAt := d.CreatedAt.Add(ttl)
+	d.ExpiresAt = &expiresAt
+
+	return nil
+}
+
+// Expired returns whether the deck expired at the given time.
+func (d *Deck) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)
+}
+
+// Sweepable returns whether the deck can be deleted at the given time, because it expired or because it is
+// exhausted or closed and wasn't changed after idleBefore.
+func (d *Deck) Sweepable(now, idleBefore time.Time) bool {
+	return d.Expired(now) || ((len(d.Cards) == 0 || d.Closed()) && !d.UpdatedAt.After(idleBefore))
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_SetTTL(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	d.CreatedAt = created
+
+	if d.Expired(created.Add(100 * 365 * 24 * time.Hour)) {
+		t.Error("Deck.Expired() = true, want decks without ttl to never expire")
+	}
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if err := d.SetTTL(ttl); !errors.Is(err, domain.ErrInvalidTTL) {
+			t.Errorf("Deck.SetTTL(%v) error = %v, want %v", ttl, err, domain.ErrInvalidTTL)
+		}
+	}
+	if err := d.SetTTL(time.Hour); err != nil {
+		t.Fatalf("Deck.SetTTL() error = %v", err)
+	}
+	if d.Expired(created.Add(time.Hour - time.Nanosecond)) {
+		t.Error("Deck.Expired() = true before the ttl passed, want false")
+	}
+	if !d.Expired(created.Add(time.Hour)) {
+		t.Error("Deck.Expired() = false once the ttl passed, want true")
+	}
+}
+
+func TestDeck_Sweepable(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expired := now.Add(-time.Second)
+
+	tests := []struct {
+		name      string
+		cards     []domain.Card
+		updatedAt time.Time
+		expiresAt *time.Time
+		closedAt  *time.Time
+		want      bool
+	}{
+		{name: "in use", cards: domain.CompleteDeckCards(), updatedAt: now.Add(-2 * time.Hour)},
+		{name: "expired", cards: domain.CompleteDeckCards(), updatedAt: now, expiresAt: &expired, want: true},
+		{name: "exhausted recently", cards: []domain.Card{}, updatedAt: now.Add(-time.Minute)},
+		{name: "exhausted and idle", cards: []domain.Card{}, updatedAt: now.Add(-2 * time.Hour), want: true},
+		{name: "closed recently", cards: domain.CompleteDeckCards(), updatedAt: now.Add(-time.Minute), closedAt: &now},
+		{name: "closed and idle", cards: domain.CompleteDeckCards(), updatedAt: now.Add(-2 * time.Hour), closedAt: &now, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &domain.Deck{Cards: tt.cards, UpdatedAt: tt.updatedAt, ExpiresAt: tt.expiresAt, ClosedAt: tt.closedAt}
+			if got := d.Sweepable(now, now.Add(-time.Hour)); got != tt.want {
+				t.Errorf("Deck.Sweepable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const serverSeedBytes = 32
+
+var (
+	// ErrCommitmentMismatch error returned when a server seed doesn't match the commitment published for it.
+	ErrCommitmentMismatch = errors.New("commitment_mismatch")
+	// ErrOrderMismatch error returned when the order of a deck doesn't match the order computed from its seeds.
+	ErrOrderMismatch = errors.New("order_mismatch")
+	// ErrFairDeckReorder error returned when reordering a provably fair deck after its creation.
+	ErrFairDeckReorder = errors.New("fair_deck_reorder")
+)
+
+// Fairness holds the commit-reveal data of a provably fair deck. The commitment is published when the deck
+// is created, and the server seed is only revealed when the deck can't be drawn from anymore.
+// Anyone can then check that the server seed matches the commitment, and that both seeds produce the order
+// of the deck, proving the deck wasn't manipulated after its creation.
+type Fairness struct {
+	ServerSeed string `json:"server_seed"`
+	ClientSeed string `json:"client_seed"`
+	Commitment string `json:"commitment"`
+}
+
+// NewFairness returns the fairness data for a new deck, with a random server seed and the client seed given.
+func NewFairness(clientSeed string) (*Fairness, error) {
+	b := make([]byte, serverSeedBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generating the server seed failed: %w", err)
+	}
+
+	serverSeed := hex.EncodeToString(b)
+
+	return &Fairness{
+		ServerSeed: serverSeed,
+		ClientSeed: clientSeed,
+		Commitment: Commit(serverSeed),
+	}, nil
+}
+
+// Commit returns the commitment of the server seed, the hex encoded SHA-256 hash of the seed.
+func Commit(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// FairSeed returns the shuffle seed derived from the server and client seeds. The seed is taken from the first
+// bytes of the HMAC-SHA256 of the client seed keyed with the server seed, so neither party controls it alone.
+func FairSeed(serverSeed, clientSeed string) int64 {
+	mac := hmac.New(sha256.New, []byte(serverSeed))
+	mac.Write([]byte(clientSeed))
+	sum := mac.Sum(nil)
+
+	return int64(binary.BigEndian.Uint64(sum[:8]) &^ (1 << 63))
+}
+
+// VerifyFairShuffle checks that the server seed matches the commitment, and that shuffling the original cards
+// with the seeds produces the order given. The original cards are the cards requested when the deck was created,
+// in the order they were requested.
+func VerifyFairShuffle(original, order []Card, f Fairness) error {
+	if Commit(f.ServerSeed) != f.Commitment {
+		return ErrCommitmentMismatch
+	}
+
+	recomputed := append([]Card(nil), original...)
+	ShuffleCards(recomputed, FairSeed(f.ServerSeed, f.ClientSeed))
+
+	if !reflect.DeepEqual(recomputed, order) {
+		return ErrOrderMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestVerifyFairShuffle(t *testing.T) {
+	f, err := domain.NewFairness("client-seed")
+	if err != nil {
+		t.Fatalf("NewFairness() error = %v", err)
+	}
+	d := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithFairness(f))
+	if d.Seed != domain.FairSeed(f.ServerSeed, f.ClientSeed) {
+		t.Fatalf("New deck seed = %d, want the seed derived from the server and client seeds", d.Seed)
+	}
+
+	tests := []struct {
+		name     string
+		order    []domain.Card
+		fairness domain.Fairness
+		wantErr  error
+	}{
+		{
+			name:     "verifies the order of a fair deck",
+			order:    d.Cards,
+			fairness: *f,
+		},
+		{
+			name:     "fails if the server seed doesn't match the commitment",
+			order:    d.Cards,
+			fairness: domain.Fairness{ServerSeed: "other", ClientSeed: f.ClientSeed, Commitment: f.Commitment},
+			wantErr:  domain.ErrCommitmentMismatch,
+		},
+		{
+			name:     "fails if the client seed was changed",
+			order:    d.Cards,
+			fairness: domain.Fairness{ServerSeed: f.ServerSeed, ClientSeed: "other", Commitment: f.Commitment},
+			wantErr:  domain.ErrOrderMismatch,
+		},
+		{
+			name: "fails if the order was manipulated",
+			order: func() []domain.Card {
+				c := append([]domain.Card(nil), d.Cards...)
+				c[0], c[1] = c[1], c[0]
+				return c
+			}(),
+			fairness: *f,
+			wantErr:  domain.ErrOrderMismatch,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := domain.VerifyFairShuffle(domain.CompleteDeckCards(), tt.order, tt.fairness); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyFairShuffle() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
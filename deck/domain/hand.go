@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrHandNotFound error returned when a player doesn't have a hand in a deck.
+	ErrHandNotFound = errors.New("hand_not_found")
+	// ErrInvalidPlayerName error returned when a player name is not valid.
+	ErrInvalidPlayerName = errors.New("invalid_player_name")
+	// ErrInvalidDeal error returned when dealing to no players, to repeated players or less than one card each.
+	ErrInvalidDeal = errors.New("invalid_deal")
+	// ErrNotEnoughCards error returned when the deck doesn't have enough cards for an operation.
+	ErrNotEnoughCards = errors.New("not_enough_cards")
+)
+
+// Hand represents the cards dealt to a player from a deck, in the order they were dealt.
+type Hand struct {
+	Player string `json:"player"`
+	Cards  []Card `json:"cards"`
+	// Visibility of the hand. See EffectiveVisibility for the default.
+	Visibility Visibility `json:"visibility"`
+}
+
+// ValidatePlayerName returns an error if the name isn't between 1 and 64 letters, digits, dashes or underscores.
+func ValidatePlayerName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidPlayerName, name)
+	}
+
+	return nil
+}
+
+// Hand returns the hand of the given player. Returns an error if the player doesn't have a hand in the deck.
+func (d *Deck) Hand(player string) (*Hand, error) {
+	for _, h := range d.Hands {
+		if h.Player == player {
+			return h, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrHandNotFound, player)
+}
+
+// Deal deals the given amount of cards to each player from the top of the deck, one card at a time in rotation,
+// adding them to the hands of the players. The hands are created for the players that don't have one.
+// Returns an error if the players or the amount are invalid or if the deck doesn't have enough cards for every
+// player, in which case the deck isn't modified.
+func (d *Deck) Deal(players []string, cardsPerPlayer int) error {
+	if len(players) == 0 {
+		return fmt.Errorf("%w: no players given", ErrInvalidDeal)
+	}
+
+	if cardsPerPlayer < 1 {
+		return fmt.Errorf("%w: each player must be dealt at least one card", ErrInvalidDeal)
+	}
+
+	seen := make(map[string]bool, len(players))
+
+	for _, p := range players {
+		if err := ValidatePlayerName(p); err != nil {
+			return err
+		}
+
+		if seen[p] {
+			return fmt.Errorf("%w: the player %q is repeated", ErrInvalidDeal, p)
+		}
+
+		seen[p] = true
+	}
+
+	if needed := len(players) * cardsPerPlayer; needed > len(d.Cards) {
+		return fmt.Errorf("%w: %d cards are needed but %d remain", ErrNotEnoughCards, needed, len(d.Cards))
+	}
+
+	hands := make([]*Hand, len(players))
+
+	for i, p := range players {
+		h, err := d.Hand(p)
+
+		if err != nil {
+			h = &Hand{Player: p}
+			d.Hands = append(d.Hands, h)
+		}
+
+		hands[i] = h
+	}
+
+	for round := 0; round < cardsPerPlayer; round++ {
+		for _, h := range hands {
+			h.Cards = append(h.Cards, d.Cards[0])
+			d.Cards = d.Cards[1:]
+		}
+	}
+
+	d.Change = &EventParams{Players: append([]string(nil), players...), Amount: cardsPerPlayer}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_Deal(t *testing.T) {
+	t.Run("deals one card at a time in rotation", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.Deal([]string{"alice", "bob"}, 2); err != nil {
+			t.Fatalf("Deck.Deal() error = %v", err)
+		}
+		c := domain.CompleteDeckCards()
+		want := []*domain.Hand{
+			{Player: "alice", Cards: []domain.Card{c[0], c[2]}},
+			{Player: "bob", Cards: []domain.Card{c[1], c[3]}},
+		}
+		if !reflect.DeepEqual(d.Hands, want) {
+			t.Errorf("Deck.Deal() hands = %v, want %v", d.Hands, want)
+		}
+		if !reflect.DeepEqual(d.Cards, c[4:]) {
+			t.Errorf("Deck.Deal() cards = %v, want %v", d.Cards, c[4:])
+		}
+	})
+	t.Run("adds the cards to the existing hands", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.Deal([]string{"alice"}, 1); err != nil {
+			t.Fatalf("Deck.Deal() error = %v", err)
+		}
+		if err := d.Deal([]string{"bob", "alice"}, 1); err != nil {
+			t.Fatalf("Deck.Deal() error = %v", err)
+		}
+		c := domain.CompleteDeckCards()
+		want := []*domain.Hand{
+			{Player: "alice", Cards: []domain.Card{c[0], c[2]}},
+			{Player: "bob", Cards: []domain.Card{c[1]}},
+		}
+		if !reflect.DeepEqual(d.Hands, want) {
+			t.Errorf("Deck.Deal() hands = %v, want %v", d.Hands, want)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		players        []string
+		cardsPerPlayer int
+		wantErr        error
+	}{
+		{name: "fails without players", cardsPerPlayer: 1, wantErr: domain.ErrInvalidDeal},
+		{name: "fails with repeated players", players: []string{"alice", "alice"}, cardsPerPlayer: 1, wantErr: domain.ErrInvalidDeal},
+		{name: "fails without cards per player", players: []string{"alice"}, wantErr: domain.ErrInvalidDeal},
+		{name: "fails with an invalid player name", players: []string{"alice smith"}, cardsPerPlayer: 1, wantErr: domain.ErrInvalidPlayerName},
+		{name: "fails without enough cards", players: []string{"alice", "bob"}, cardsPerPlayer: 2, wantErr: domain.ErrNotEnoughCards},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			d := testDeck()
+			if err := d.Deal(tt.players, tt.cardsPerPlayer); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Deck.Deal() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d, testDeck()) {
+				t.Errorf("Deck after a failed Deal() = %v, want it unmodified", d)
+			}
+		})
+	}
+}
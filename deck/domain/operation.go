@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+)
+
+// MaxShuffleTimes is the maximum number of times a riffle or overhand shuffle can be repeated in one operation.
+const MaxShuffleTimes = 100
+
+var (
+	// ErrInvalidCutIndex error returned when cutting a deck at an index outside of it.
+	ErrInvalidCutIndex = errors.New("invalid_cut_index")
+	// ErrInvalidShuffleTimes error returned when repeating a shuffle less than once or more than MaxShuffleTimes.
+	ErrInvalidShuffleTimes = errors.New("invalid_shuffle_times")
+)
+
+// OperationType names an operation changing the order of a deck.
+type OperationType string
+
+const (
+	// CutOperation moves the cards above an index to the bottom of the deck.
+	CutOperation OperationType = "cut"
+	// RiffleOperation riffle shuffles the deck.
+	RiffleOperation OperationType = "riffle"
+	// OverhandOperation overhand shuffles the deck.
+	OverhandOperation OperationType = "overhand"
+	// ReshuffleOperation fully reshuffles the deck.
+	ReshuffleOperation OperationType = "reshuffle"
+)
+
+// Operation records an operation that changed the order of a deck after its creation, with its parameters.
+// The operations driven by a seed can be reproduced applying them with the same seed to the same cards.
+type Operation struct {
+	Type OperationType `json:"type"`
+	// Index at which the deck was cut. Only set by cuts.
+	Index int `json:"index,omitempty"`
+	// Times the shuffle was repeated. Only set by riffle and overhand shuffles.
+	Times int `json:"times,omitempty"`
+	// Seed driving the randomness of the operation. Not set by cuts at a given index nor by secure reshuffles.
+	Seed int64 `json:"seed,omitempty"`
+	// Strategy used to reshuffle the deck. Only set by reshuffles.
+	Strategy ShuffleStrategy `json:"strategy,omitempty"`
+}
+
+// Cut moves the cards above the given index to the bottom of the deck, so the card at the index ends on top.
+// Returns an error if the index is outside of the deck.
+func (d *Deck) Cut(index int) error {
+	if index < 0 || index > len(d.Cards) {
+		return fmt.Errorf("%w: %d", ErrInvalidCutIndex, index)
+	}
+
+	d.cut(index)
+	d.applied(Operation{Type: CutOperation, Index: index})
+
+	return nil
+}
+
+// CutRandom cuts the deck at a random index chosen with the given seed, leaving at least one card on each side
+// when the deck has more than one card. Returns the index of the cut.
+func (d *Deck) CutRandom(seed int64) int {
+	index := 0
+
+	if len(d.Cards) > 1 {
+		index = 1 + mathrand.New(mathrand.NewSource(seed)).Intn(len(d.Cards)-1)
+	}
+
+	d.cut(index)
+	d.applied(Operation{Type: CutOperation, Index: index, Seed: seed})
+
+	return index
+}
+
+// applied records the operation as applied to the deck, and as the change to record in its next event.
+func (d *Deck) applied(op Operation) {
+	d.Operations = append(d.Operations, op)
+	d.Change = &EventParams{Operation: &op}
+}
+
+// replayOperation applies again the given cut, riffle or overhand shuffle, as recorded.
+func (d *Deck) replayOperation(op Operation) error {
+	switch op.Type {
+	case CutOperation:
+		if op.Index < 0 || op.Index > len(d.Cards) {
+			return fmt.Errorf("%w: %d", ErrInvalidCutIndex, op.Index)
+		}
+
+		d.cut(op.Index)
+		d.applied(op)
+
+		return nil
+	case RiffleOperation:
+		return d.Riffle(op.Times, op.Seed)
+	case OverhandOperation:
+		return d.Overhand(op.Times, op.Seed)
+	default:
+		return fmt.Errorf("operations of type %q can't be replayed", op.Type)
+	}
+}
+
+func (d *Deck) cut(index int) {
+	cards := make([]Card, 0, len(d.Cards))
+	cards = append(cards, d.Cards[index:]...)
+	d.Cards = append(cards, d.Cards[:index]...)
+}
+
+// Riffle riffle shuffles the deck the given number of times following the Gilbreath–Shannon–Reeds model: the deck
+// is cut in two packets with a binomially distributed size, and the cards are dropped from either packet with a
+// probability proportional to its size. The randomness is driven by the given seed.
+// Returns an error if the times aren't between 1 and MaxShuffleTimes.
+func (d *Deck) Riffle(times int, seed int64) error {
+	if err := validateShuffleTimes(times); err != nil {
+		return err
+	}
+
+	r := mathrand.New(mathrand.NewSource(seed))
+
+	for i := 0; i < times; i++ {
+		riffle(d.Cards, r)
+	}
+
+	d.applied(Operation{Type: RiffleOperation, Times: times, Seed: seed})
+
+	return nil
+}
+
+func riffle(cards []Card, r *mathrand.Rand) {
+	cut := 0
+
+	for range cards {
+		cut += r.Intn(2)
+	}
+
+	left := append([]Card(nil), cards[:cut]...)
+	right := append([]Card(nil), cards[cut:]...)
+
+	for i := range cards {
+		if r.Intn(len(left)+len(right)) < len(left) {
+			cards[i], left = left[0], left[1:]
+		} else {
+			cards[i], right = right[0], right[1:]
+		}
+	}
+}
+
+// Overhand overhand shuffles the deck the given number of times: small packets are taken from the top of the deck
+// and piled one over the other, reversing the order of the packets while each packet keeps its own order.
+// The size of the packets is driven by the given seed. Returns an error if the times aren't between 1 and
+// MaxShuffleTimes.
+func (d *Deck) Overhand(times int, seed int64) error {
+	if err := validateShuffleTimes(times); err != nil {
+		return err
+	}
+
+	r := mathrand.New(mathrand.NewSource(seed))
+
+	for i := 0; i < times; i++ {
+		d.Cards = overhand(d.Cards, r)
+	}
+
+	d.applied(Operation{Type: OverhandOperation, Times: times, Seed: seed})
+
+	return nil
+}
+
+func overhand(cards []Card, r *mathrand.Rand) []Card {
+	// Packets of up to a sixth of the deck, about 8 cards for a complete deck, as dealers usually run.
+	maxPacket := len(cards) / 6
+
+	if maxPacket < 1 {
+		maxPacket = 1
+	}
+
+	shuffled := make([]Card, len(cards))
+	end := len(shuffled)
+
+	for len(cards) > 0 {
+		size := 1 + r.Intn(maxPacket)
+
+		if size > len(cards) {
+			size = len(cards)
+		}
+
+		copy(shuffled[end-size:end], cards[:size])
+		cards = cards[size:]
+		end -= size
+	}
+
+	return shuffled
+}
+
+func validateShuffleTimes(times int) error {
+	if times < 1 || times > MaxShuffleTimes {
+		return fmt.Errorf("%w: %d", ErrInvalidShuffleTimes, times)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func sortedCards(cards []domain.Card) []domain.Card {
+	sorted := append([]domain.Card(nil), cards...)
+	domain.SortCards(sorted, domain.BySuit.Then(domain.ByRank))
+	return sorted
+}
+
+func TestDeck_Cut(t *testing.T) {
+	t.Run("moves the cards above the index to the bottom", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.Cut(10); err != nil {
+			t.Fatalf("Deck.Cut() error = %v", err)
+		}
+		want := append(domain.CompleteDeckCards()[10:], domain.CompleteDeckCards()[:10]...)
+		if !reflect.DeepEqual(d.Cards, want) {
+			t.Errorf("Deck.Cut() = %v, want %v", d.Cards, want)
+		}
+		wantOps := []domain.Operation{{Type: domain.CutOperation, Index: 10}}
+		if !reflect.DeepEqual(d.Operations, wantOps) {
+			t.Errorf("Deck.Cut() operations = %v, want %v", d.Operations, wantOps)
+		}
+	})
+	t.Run("returns an error if the index is outside of the deck", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		for _, index := range []int{-1, 53} {
+			if err := d.Cut(index); !errors.Is(err, domain.ErrInvalidCutIndex) {
+				t.Errorf("Deck.Cut(%d) error = %v, want %v", index, err, domain.ErrInvalidCutIndex)
+			}
+		}
+		if d.Operations != nil {
+			t.Errorf("Deck.Cut() recorded the failed operations %v", d.Operations)
+		}
+	})
+	t.Run("cuts at a random index reproducible from the seed", func(t *testing.T) {
+		first := domain.NewDeck(false, domain.CompleteDeckCards())
+		second := domain.NewDeck(false, domain.CompleteDeckCards())
+		index := first.CutRandom(7)
+		if index < 1 || index > 51 || second.CutRandom(7) != index {
+			t.Errorf("Deck.CutRandom() = %d, want the same index between 1 and 51 for the same seed", index)
+		}
+		if !reflect.DeepEqual(first.Cards, second.Cards) {
+			t.Errorf("Deck.CutRandom() with the same seed = %v and %v, want the same order", first.Cards, second.Cards)
+		}
+	})
+}
+
+func TestDeck_Riffle(t *testing.T) {
+	t.Run("shuffles reproducibly from the seed keeping the cards", func(t *testing.T) {
+		first := domain.NewDeck(false, domain.CompleteDeckCards())
+		second := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := first.Riffle(7, 11); err != nil {
+			t.Fatalf("Deck.Riffle() error = %v", err)
+		}
+		if err := second.Riffle(7, 11); err != nil {
+			t.Fatalf("Deck.Riffle() error = %v", err)
+		}
+		if !reflect.DeepEqual(first.Cards, second.Cards) {
+			t.Errorf("Deck.Riffle() with the same seed = %v and %v, want the same order", first.Cards, second.Cards)
+		}
+		if reflect.DeepEqual(first.Cards, domain.CompleteDeckCards()) {
+			t.Error("Deck.Riffle() didn't change the order of the deck")
+		}
+		if !reflect.DeepEqual(sortedCards(first.Cards), sortedCards(domain.CompleteDeckCards())) {
+			t.Errorf("Deck.Riffle() = %v, want the same cards", first.Cards)
+		}
+		wantOps := []domain.Operation{{Type: domain.RiffleOperation, Times: 7, Seed: 11}}
+		if !reflect.DeepEqual(first.Operations, wantOps) {
+			t.Errorf("Deck.Riffle() operations = %v, want %v", first.Operations, wantOps)
+		}
+	})
+	t.Run("keeps the relative order of each packet in a single riffle", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.Riffle(1, 5); err != nil {
+			t.Fatalf("Deck.Riffle() error = %v", err)
+		}
+		// A single riffle interleaves two packets, so the deck has at most two rising sequences.
+		// The rising sequences are counted as the original neighbours that end in reverse order.
+		position := map[domain.Card]int{}
+		for i, c := range d.Cards {
+			position[c] = i
+		}
+		complete := domain.CompleteDeckCards()
+		rising := 1
+		for i := 1; i < len(complete); i++ {
+			if position[complete[i]] < position[complete[i-1]] {
+				rising++
+			}
+		}
+		if rising > 2 {
+			t.Errorf("Deck.Riffle() = %v, has %d rising sequences, want at most 2", d.Cards, rising)
+		}
+	})
+	t.Run("returns an error if the times are invalid", func(t *testing.T) {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		for _, times := range []int{0, domain.MaxShuffleTimes + 1} {
+			if err := d.Riffle(times, 1); !errors.Is(err, domain.ErrInvalidShuffleTimes) {
+				t.Errorf("Deck.Riffle(%d) error = %v, want %v", times, err, domain.ErrInvalidShuffleTimes)
+			}
+		}
+	})
+}
+
+func TestDeck_Overhand(t *testing.T) {
+	t.Run("shuffles reproducibly from the seed keeping the cards", func(t *testing.T) {
+		first := domain.NewDeck(false, domain.CompleteDeckCards())
+		second := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := first.Overhand(3, 11); err != nil {
+			t.Fatalf("Deck.Overhand() error = %v", err)
+		}
+		if err := second.Overhand(3, 11); err != nil {
+			t.Fatalf("Deck.Overhand() error = %v", err)
+		}
+		if !reflect.DeepEqual(first.Cards, second.Cards) {
+			t.Errorf("Deck.Overhand() with the same seed = %v and %v, want the same order", first.Cards, second.Cards)
+		}
+		if !reflect.DeepEqual(sortedCards(first.Cards), sortedCards(domain.CompleteDeckCards())) {
+			t.Errorf("Deck.Overhand() = %v, want the same cards", first.Cards)
+		}
+		wantOps := []domain.Operation{{Type: domain.OverhandOperation, Times: 3, Seed: 11}}
+		if !reflect.DeepEqual(first.Operations, wantOps) {
+			t.Errorf("Deck.Overhand() operations = %v, want %v", first.Operations, wantOps)
+		}
+	})
+	t.Run("reverses the packets of a small deck", func(t *testing.T) {
+		// With less than 6 cards every packet has a single card, so the deck is reversed.
+		d := testDeck()
+		if err := d.Overhand(1, 1); err != nil {
+			t.Fatalf("Deck.Overhand() error = %v", err)
+		}
+		want := []domain.Card{{Rank: domain.Five, Suit: domain.Spades}, {Rank: domain.Four, Suit: domain.Hearts}}
+		if !reflect.DeepEqual(d.Cards, want) {
+			t.Errorf("Deck.Overhand() = %v, want %v", d.Cards, want)
+		}
+	})
+}
+
+func TestDeck_Reshuffle_RecordsOperation(t *testing.T) {
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	d.Reshuffle(domain.SeededShuffler{Seed: 4})
+	d.Reshuffle(domain.SecureShuffler{})
+	want := []domain.Operation{
+		{Type: domain.ReshuffleOperation, Strategy: domain.SeededShuffle, Seed: 4},
+		{Type: domain.ReshuffleOperation, Strategy: domain.SecureShuffle},
+	}
+	if !reflect.DeepEqual(d.Operations, want) {
+		t.Errorf("Deck.Reshuffle() operations = %v, want %v", d.Operations, want)
+	}
+}
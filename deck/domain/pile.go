@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrPileNotFound error returned when a pile is not found in a deck.
+	ErrPileNotFound = errors.New("pile_not_found")
+	// ErrInvalidPileName error returned when a pile name is not valid.
+	ErrInvalidPileName = errors.New("invalid_pile_name")
+	// ErrCardsNotDrawn error returned when using cards that weren't drawn from the deck or are already in a pile.
+	ErrCardsNotDrawn = errors.New("cards_not_drawn")
+)
+
+// nameRegexp matches the valid names of piles and players.
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CardsNotDrawnError lists the cards that couldn't be used because they weren't drawn from the deck.
+type CardsNotDrawnError struct {
+	Codes []string
+}
+
+func (e *CardsNotDrawnError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCardsNotDrawn, strings.Join(e.Codes, ","))
+}
+
+// Unwrap allows to match the error against ErrCardsNotDrawn.
+func (e *CardsNotDrawnError) Unwrap() error {
+	return ErrCardsNotDrawn
+}
+
+// Pile represents a named collection of cards attached to a deck, such as a discard pile, a player's hand
+// or a community pile. As in the deck, the first card is the top of the pile.
+type Pile struct {
+	Name  string `json:"name"`
+	Cards []Card `json:"cards"`
+	// Visibility of the pile. See EffectiveVisibility for the default.
+	Visibility Visibility `json:"visibility"`
+}
+
+// ValidatePileName returns an error if the name isn't between 1 and 64 letters, digits, dashes or underscores.
+func ValidatePileName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidPileName, name)
+	}
+
+	return nil
+}
+
+// Pile returns the pile with the given name. Returns an error if the deck doesn't have it.
+func (d *Deck) Pile(name string) (*Pile, error) {
+	for _, p := range d.Piles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrPileNotFound, name)
+}
+
+// AddToPile places the given drawn cards on top of the pile one by one, so the last card given ends on top.
+// The pile is created if the deck doesn't have it. A card without deck index matches a drawn card of any deck
+// of a shoe. Returns an error listing the cards that aren't drawn, in which case the deck isn't modified.
+func (d *Deck) AddToPile(name string, cards []Card) error {
+	if err := ValidatePileName(name); err != nil {
+		return err
+	}
+
+	remaining, taken, err := d.takeDrawn(cards)
+
+	if err != nil {
+		return err
+	}
+
+	p, err := d.Pile(name)
+
+	if err != nil {
+		p = &Pile{Name: name}
+		d.Piles = append(d.Piles, p)
+	}
+
+	pileCards := make([]Card, 0, len(taken)+len(p.Cards))
+
+	for i := len(taken) - 1; i >= 0; i-- {
+		pileCards = append(pileCards, taken[i])
+	}
+
+	p.Cards = append(pileCards, p.Cards...)
+	d.Drawn = remaining
+	d.Change = &EventParams{Pile: name, Cards: append([]Card(nil), taken...)}
+
+	return nil
+}
+
+// DrawFromPile draws the amount of cards given from the top of the pile.
+// If the amount given is more than the number of cards in the pile, draws all the cards available.
+// Returns an error if the deck doesn't have the pile.
+func (d *Deck) DrawFromPile(name string, amount int) ([]Card, error) {
+	p, err := d.Pile(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if amount > len(p.Cards) {
+		amount = len(p.Cards)
+	}
+
+	drawnCards := append([]Card(nil), p.Cards[:amount]...)
+	p.Cards = p.Cards[amount:]
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.Change = &EventParams{Pile: name, Amount: amount}
+
+	return drawnCards, nil
+}
+
+// ShufflePile shuffles the cards of the pile with the given shuffler. Returns an error if the deck doesn't have the pile.
+func (d *Deck) ShufflePile(name string, s Shuffler) error {
+	p, err := d.Pile(name)
+
+	if err != nil {
+		return err
+	}
+
+	s.Shuffle(p.Cards)
+	d.Change = nil
+
+	// Only seeded shuffles can be replayed.
+	if seeded, ok := s.(SeededShuffler); ok {
+		d.Change = &EventParams{Pile: name, Seed: seeded.Seed}
+	}
+
+	return nil
+}
+
+// takeDrawn removes the given cards from the drawn cards of the deck, returning the remaining drawn cards and the
+// matched ones. Returns an error listing every card that isn't drawn.
+func (d *Deck) takeDrawn(cards []Card) ([]Card, []Card, error) {
+	remaining, taken, missing := takeCards(d.Drawn, cards)
+
+	if len(missing) > 0 {
+		return nil, nil, &CardsNotDrawnError{Codes: missing}
+	}
+
+	return remaining, taken, nil
+}
+
+// takeCards removes the wanted cards from the available ones, returning the remaining cards, the matched ones and
+// the codes of the wanted cards that aren't available.
+func takeCards(available, wanted []Card) ([]Card, []Card, []string) {
+	remaining := append([]Card(nil), available...)
+	taken := make([]Card, 0, len(wanted))
+
+	var missing []string
+
+	for _, w := range wanted {
+		i := indexOfCard(remaining, w)
+
+		if i < 0 {
+			missing = append(missing, w.Code())
+			continue
+		}
+
+		taken = append(taken, remaining[i])
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+
+	return remaining, taken, missing
+}
+
+// indexOfCard returns the index of the first card matching the wanted one, or -1 if none matches.
+// A card without deck index matches the same card of any deck.
+func indexOfCard(cards []Card, wanted Card) int {
+	for i, c := range cards {
+		if c.Rank == wanted.Rank && c.Suit == wanted.Suit && (wanted.DeckIndex == 0 || wanted.DeckIndex == c.DeckIndex) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,136 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_AddToPile(t *testing.T) {
+	aceSpades := domain.Card{Rank: domain.Ace, Suit: domain.Spades}
+	kingDiamonds := domain.Card{Rank: domain.King, Suit: domain.Diamonds}
+	twoClubs := domain.Card{Rank: domain.Two, Suit: domain.Clubs}
+	tests := []struct {
+		name     string
+		deck     *domain.Deck
+		pile     string
+		cards    []domain.Card
+		wantDeck *domain.Deck
+		wantErr  error
+	}{
+		{
+			name:  "creates the pile with the last card given on top",
+			deck:  &domain.Deck{Drawn: []domain.Card{aceSpades, kingDiamonds, twoClubs}},
+			pile:  "discard",
+			cards: []domain.Card{aceSpades, kingDiamonds},
+			wantDeck: &domain.Deck{
+				Drawn:  []domain.Card{twoClubs},
+				Piles:  []*domain.Pile{{Name: "discard", Cards: []domain.Card{kingDiamonds, aceSpades}}},
+				Change: &domain.EventParams{Pile: "discard", Cards: []domain.Card{aceSpades, kingDiamonds}},
+			},
+		},
+		{
+			name:  "places the cards on top of an existing pile",
+			deck:  &domain.Deck{Drawn: []domain.Card{twoClubs}, Piles: []*domain.Pile{{Name: "discard", Cards: []domain.Card{aceSpades}}}},
+			pile:  "discard",
+			cards: []domain.Card{twoClubs},
+			wantDeck: &domain.Deck{
+				Drawn:  []domain.Card{},
+				Piles:  []*domain.Pile{{Name: "discard", Cards: []domain.Card{twoClubs, aceSpades}}},
+				Change: &domain.EventParams{Pile: "discard", Cards: []domain.Card{twoClubs}},
+			},
+		},
+		{
+			name:  "matches a drawn card of any deck of a shoe",
+			deck:  &domain.Deck{Drawn: []domain.Card{{Rank: domain.Ace, Suit: domain.Spades, DeckIndex: 2}}},
+			pile:  "hand",
+			cards: []domain.Card{aceSpades},
+			wantDeck: &domain.Deck{
+				Drawn:  []domain.Card{},
+				Piles:  []*domain.Pile{{Name: "hand", Cards: []domain.Card{{Rank: domain.Ace, Suit: domain.Spades, DeckIndex: 2}}}},
+				Change: &domain.EventParams{Pile: "hand", Cards: []domain.Card{{Rank: domain.Ace, Suit: domain.Spades, DeckIndex: 2}}},
+			},
+		},
+		{
+			name:     "returns an error if a card wasn't drawn",
+			deck:     &domain.Deck{Drawn: []domain.Card{aceSpades}},
+			pile:     "discard",
+			cards:    []domain.Card{aceSpades, kingDiamonds},
+			wantDeck: &domain.Deck{Drawn: []domain.Card{aceSpades}},
+			wantErr:  domain.ErrCardsNotDrawn,
+		},
+		{
+			name:     "returns an error if the pile name is invalid",
+			deck:     &domain.Deck{Drawn: []domain.Card{aceSpades}},
+			pile:     "my pile",
+			cards:    []domain.Card{aceSpades},
+			wantDeck: &domain.Deck{Drawn: []domain.Card{aceSpades}},
+			wantErr:  domain.ErrInvalidPileName,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.deck.AddToPile(tt.pile, tt.cards)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Deck.AddToPile() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.deck, tt.wantDeck) {
+				t.Errorf("Deck after AddToPile() = %v, want %v", tt.deck, tt.wantDeck)
+			}
+		})
+	}
+}
+
+func TestDeck_AddToPile_ListsMissingCards(t *testing.T) {
+	d := &domain.Deck{}
+	err := d.AddToPile("discard", []domain.Card{{Rank: domain.Ace, Suit: domain.Spades}, {Rank: domain.Ten, Suit: domain.Hearts}})
+	var notDrawnErr *domain.CardsNotDrawnError
+	if !errors.As(err, &notDrawnErr) {
+		t.Fatalf("Deck.AddToPile() error = %v, want a CardsNotDrawnError", err)
+	}
+	if want := []string{"AS", "10H"}; !reflect.DeepEqual(notDrawnErr.Codes, want) {
+		t.Errorf("Deck.AddToPile() missing cards = %v, want %v", notDrawnErr.Codes, want)
+	}
+}
+
+func TestDeck_DrawFromPile(t *testing.T) {
+	aceSpades := domain.Card{Rank: domain.Ace, Suit: domain.Spades}
+	kingDiamonds := domain.Card{Rank: domain.King, Suit: domain.Diamonds}
+	t.Run("draws from the top of the pile", func(t *testing.T) {
+		d := &domain.Deck{Piles: []*domain.Pile{{Name: "discard", Cards: []domain.Card{kingDiamonds, aceSpades}}}}
+		got, err := d.DrawFromPile("discard", 5)
+		if err != nil {
+			t.Fatalf("Deck.DrawFromPile() error = %v", err)
+		}
+		if want := []domain.Card{kingDiamonds, aceSpades}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Deck.DrawFromPile() = %v, want %v", got, want)
+		}
+		if !reflect.DeepEqual(d.Drawn, got) || len(d.Piles[0].Cards) != 0 {
+			t.Errorf("Deck after DrawFromPile() = %v, want the cards moved to the drawn cards", d)
+		}
+	})
+	t.Run("returns an error if the pile doesn't exist", func(t *testing.T) {
+		d := &domain.Deck{}
+		if _, err := d.DrawFromPile("discard", 1); !errors.Is(err, domain.ErrPileNotFound) {
+			t.Errorf("Deck.DrawFromPile() error = %v, want %v", err, domain.ErrPileNotFound)
+		}
+	})
+}
+
+func TestDeck_ShufflePile(t *testing.T) {
+	d := &domain.Deck{Piles: []*domain.Pile{{Name: "discard", Cards: domain.CompleteDeckCards()}}}
+	if err := d.ShufflePile("discard", domain.SeededShuffler{Seed: 42}); err != nil {
+		t.Fatalf("Deck.ShufflePile() error = %v", err)
+	}
+	want := domain.CompleteDeckCards()
+	domain.ShuffleCards(want, 42)
+	if !reflect.DeepEqual(d.Piles[0].Cards, want) {
+		t.Errorf("Deck.ShufflePile() = %v, want %v", d.Piles[0].Cards, want)
+	}
+	if err := d.ShufflePile("hand", domain.SeededShuffler{Seed: 42}); !errors.Is(err, domain.ErrPileNotFound) {
+		t.Errorf("Deck.ShufflePile() error = %v, want %v", err, domain.ErrPileNotFound)
+	}
+}
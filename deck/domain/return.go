@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidPosition error returned when a position of the deck is unknown.
+	ErrInvalidPosition = errors.New("invalid_position")
+)
+
+// Position names where cards are placed in the deck.
+type Position string
+
+const (
+	// PositionTop is the top of the deck, the first cards to be drawn.
+	PositionTop Position = "top"
+	// PositionBottom is the bottom of the deck, the last cards to be drawn.
+	PositionBottom Position = "bottom"
+	// PositionRandom is a random position of the deck, chosen independently for every card.
+	PositionRandom Position = "random"
+)
+
+// ParsePosition returns the position with the given name.
+func ParsePosition(name string) (Position, error) {
+	switch p := Position(name); p {
+	case PositionTop, PositionBottom, PositionRandom:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidPosition, name)
+	}
+}
+
+// Return places the given drawn cards back in the deck at the given position. Returned to the top or the bottom,
+// the cards keep the order given, so the first card given is the first one to be drawn of them. Returned to random
+// positions, the shuffler decides where each card goes while the rest of the deck keeps its order.
+// A card without deck index matches a drawn card of any deck of a shoe. Returns an error listing the cards that
+// aren't drawn, in which case the deck isn't modified.
+func (d *Deck) Return(cards []Card, position Position, s Shuffler) error {
+	if _, err := ParsePosition(string(position)); err != nil {
+		return err
+	}
+
+	remaining, taken, err := d.takeDrawn(cards)
+
+	if err != nil {
+		return err
+	}
+
+	d.Change = &EventParams{Cards: append([]Card(nil), taken...), Position: position}
+
+	switch position {
+	case PositionTop:
+		d.Cards = append(taken, d.Cards...)
+	case PositionBottom:
+		d.Cards = append(d.Cards, taken...)
+	case PositionRandom:
+		d.Cards = insertRandomly(d.Cards, taken, s)
+
+		if seeded, ok := s.(SeededShuffler); ok {
+			d.Change.Seed = seeded.Seed
+		} else {
+			d.Change = nil
+		}
+	}
+
+	d.Drawn = remaining
+
+	return nil
+}
+
+// insertRandomly inserts the cards at random positions of the deck cards, keeping the order of the deck cards.
+// The cards are shuffled among as many empty slots as deck cards, which are then filled with the deck cards in order.
+// Every card is valid, so no card is mistaken for an empty slot.
+func insertRandomly(deckCards, cards []Card, s Shuffler) []Card {
+	slots := make([]Card, len(deckCards)+len(cards))
+	copy(slots, cards)
+	s.Shuffle(slots)
+
+	next := 0
+
+	for i := range slots {
+		if slots[i] == (Card{}) {
+			slots[i] = deckCards[next]
+			next++
+		}
+	}
+
+	return slots
+}
+
+// Reshuffle shuffles the cards remaining in the deck with the given shuffler. The drawn cards and the piles are
+// left untouched. An unshuffled deck becomes shuffled with the seeded strategy.
+func (d *Deck) Reshuffle(s Shuffler) {
+	s.Shuffle(d.Cards)
+	d.Shuffled = true
+
+	if d.ShuffleStrategy == "" {
+		d.ShuffleStrategy = SeededShuffle
+	}
+
+	op := Operation{Type: ReshuffleOperation}
+
+	switch s := s.(type) {
+	case SeededShuffler:
+		op.Strategy = SeededShuffle
+		op.Seed = s.Seed
+	case SecureShuffler:
+		op.Strategy = SecureShuffle
+	}
+
+	d.applied(op)
+
+	// Secure reshuffles can't be replayed.
+	if op.Strategy != SeededShuffle {
+		d.Change = nil
+	}
+}
+
+// Reset gathers the drawn cards and the cards of every pile and hand back into the deck, restoring its original
+// composition, and reshuffles it with the given shuffler. The piles and the hands are removed.
+func (d *Deck) Reset(s Shuffler) {
+	cards := append(append([]Card(nil), d.Cards...), d.Drawn...)
+
+	for _, p := range d.Piles {
+		cards = append(cards, p.Cards...)
+	}
+
+	for _, h := range d.Hands {
+		cards = append(cards, h.Cards...)
+	}
+
+	d.Cards = cards
+	d.Drawn = nil
+	d.Piles = nil
+	d.Hands = nil
+	d.Reshuffle(s)
+}
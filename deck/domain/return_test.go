@@ -0,0 +1,119 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_Return(t *testing.T) {
+	aceSpades := domain.Card{Rank: domain.Ace, Suit: domain.Spades}
+	kingDiamonds := domain.Card{Rank: domain.King, Suit: domain.Diamonds}
+	twoClubs := domain.Card{Rank: domain.Two, Suit: domain.Clubs}
+	tests := []struct {
+		name      string
+		position  domain.Position
+		cards     []domain.Card
+		wantCards []domain.Card
+		wantErr   error
+	}{
+		{
+			name:      "returns the cards to the top in the order given",
+			position:  domain.PositionTop,
+			cards:     []domain.Card{aceSpades, kingDiamonds},
+			wantCards: []domain.Card{aceSpades, kingDiamonds, twoClubs},
+		},
+		{
+			name:      "returns the cards to the bottom in the order given",
+			position:  domain.PositionBottom,
+			cards:     []domain.Card{kingDiamonds, aceSpades},
+			wantCards: []domain.Card{twoClubs, kingDiamonds, aceSpades},
+		},
+		{
+			name:      "returns an error if a card wasn't drawn",
+			position:  domain.PositionTop,
+			cards:     []domain.Card{twoClubs},
+			wantCards: []domain.Card{twoClubs},
+			wantErr:   domain.ErrCardsNotDrawn,
+		},
+		{
+			name:      "returns an error if the position is unknown",
+			position:  domain.Position("middle"),
+			cards:     []domain.Card{aceSpades},
+			wantCards: []domain.Card{twoClubs},
+			wantErr:   domain.ErrInvalidPosition,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			d := &domain.Deck{Cards: []domain.Card{twoClubs}, Drawn: []domain.Card{aceSpades, kingDiamonds}}
+			err := d.Return(tt.cards, tt.position, domain.SeededShuffler{Seed: 1})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Deck.Return() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.Cards, tt.wantCards) {
+				t.Errorf("Deck.Return() cards = %v, want %v", d.Cards, tt.wantCards)
+			}
+		})
+	}
+}
+
+func TestDeck_Return_Random(t *testing.T) {
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	drawn := d.Draw(10)
+	returned := append([]domain.Card(nil), drawn[:4]...)
+	undrawn := append([]domain.Card(nil), d.Cards...)
+
+	if err := d.Return(returned, domain.PositionRandom, domain.SeededShuffler{Seed: 7}); err != nil {
+		t.Fatalf("Deck.Return() error = %v", err)
+	}
+	if len(d.Cards) != len(undrawn)+len(returned) || len(d.Drawn) != 6 {
+		t.Fatalf("Deck.Return() = %d cards and %d drawn, want %d and 6", len(d.Cards), len(d.Drawn), len(undrawn)+len(returned))
+	}
+
+	var kept []domain.Card
+	for _, c := range d.Cards {
+		if !containsCard(returned, c) {
+			kept = append(kept, c)
+		}
+	}
+	if !reflect.DeepEqual(kept, undrawn) {
+		t.Errorf("Deck.Return() changed the order of the remaining cards = %v, want %v", kept, undrawn)
+	}
+}
+
+func containsCard(cards []domain.Card, c domain.Card) bool {
+	for _, card := range cards {
+		if card == c {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeck_Reset(t *testing.T) {
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	d.Draw(10)
+	if err := d.AddToPile("discard", d.Drawn[:3]); err != nil {
+		t.Fatalf("Deck.AddToPile() error = %v", err)
+	}
+
+	d.Reset(domain.SeededShuffler{Seed: 3})
+
+	if len(d.Cards) != 52 || d.Drawn != nil || d.Piles != nil {
+		t.Fatalf("Deck.Reset() = %d cards, drawn %v and piles %v, want 52 cards only", len(d.Cards), d.Drawn, d.Piles)
+	}
+	if !d.Shuffled || d.ShuffleStrategy != domain.SeededShuffle {
+		t.Errorf("Deck.Reset() should leave the deck shuffled with the seeded strategy")
+	}
+	got := append([]domain.Card(nil), d.Cards...)
+	domain.SortCards(got, domain.BySuit.Then(domain.ByRank))
+	want := domain.CompleteDeckCards()
+	domain.SortCards(want, domain.BySuit.Then(domain.ByRank))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Deck.Reset() cards = %v, want the complete deck", got)
+	}
+}
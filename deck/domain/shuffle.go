@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+)
+
+var (
+	// ErrInvalidShuffleStrategy error returned when a shuffle strategy is unknown.
+	ErrInvalidShuffleStrategy = errors.New("invalid_shuffle_strategy")
+)
+
+// ShuffleStrategy names the algorithm used to shuffle a deck.
+type ShuffleStrategy string
+
+const (
+	// SeededShuffle shuffles with a pseudo-random generator seeded with the seed of the deck.
+	// The order is reproducible from the seed, but predictable for anyone knowing it.
+	SeededShuffle ShuffleStrategy = "seeded"
+	// SecureShuffle shuffles with numbers read from crypto/rand. The order is unpredictable and can't be reproduced.
+	SecureShuffle ShuffleStrategy = "secure"
+)
+
+// ParseShuffleStrategy returns the shuffle strategy with the given name.
+func ParseShuffleStrategy(name string) (ShuffleStrategy, error) {
+	switch s := ShuffleStrategy(name); s {
+	case SeededShuffle, SecureShuffle:
+		return s, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidShuffleStrategy, name)
+	}
+}
+
+// Shuffler shuffles cards in place.
+type Shuffler interface {
+	Shuffle(cards []Card)
+}
+
+// SeededShuffler shuffles cards with a Fisher–Yates shuffle driven by a pseudo-random generator.
+// Shuffling the same cards with the same seed always produces the same order.
+type SeededShuffler struct {
+	Seed int64
+}
+
+// Shuffle shuffles the cards in place.
+func (s SeededShuffler) Shuffle(cards []Card) {
+	r := mathrand.New(mathrand.NewSource(s.Seed))
+	r.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+}
+
+// SecureShuffler shuffles cards with a Fisher–Yates shuffle driven by a cryptographically secure random source.
+// The indexes are drawn with rejection sampling, so every permutation is equally likely.
+type SecureShuffler struct {
+	// Reader is the source of random bytes, crypto/rand.Reader if nil.
+	Reader io.Reader
+}
+
+// Shuffle shuffles the cards in place. Panics if the random source fails, as crypto/rand never should.
+func (s SecureShuffler) Shuffle(cards []Card) {
+	reader := s.Reader
+
+	if reader == nil {
+		reader = rand.Reader
+	}
+
+	for i := len(cards) - 1; i > 0; i-- {
+		j := uniformIndex(reader, uint64(i+1))
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+// uniformIndex returns a uniformly distributed number in [0, n). The random numbers falling in the last
+// incomplete range of size n are rejected, otherwise the lower numbers would be more likely.
+func uniformIndex(reader io.Reader, n uint64) uint64 {
+	limit := math.MaxUint64 - math.MaxUint64%n
+
+	var b [8]byte
+
+	for {
+		if _, err := io.ReadFull(reader, b[:]); err != nil {
+			panic("reading random bytes failed: " + err.Error())
+		}
+
+		if v := binary.BigEndian.Uint64(b[:]); v < limit {
+			return v % n
+		}
+	}
+}
+
+// SeedSource provides the seeds used to shuffle the decks. Both rand.Source and *rand.Rand implement it.
+type SeedSource interface {
+	Int63() int64
+}
+
+type cryptoSeedSource struct{}
+
+// Int63 returns a non-negative seed read from crypto/rand. It is safe for concurrent use.
+func (cryptoSeedSource) Int63() int64 {
+	var b [8]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("reading random bytes failed: " + err.Error())
+	}
+
+	return int64(binary.BigEndian.Uint64(b[:]) &^ (1 << 63))
+}
+
+// DefaultSeedSource returns the seed source used when none is given. It is safe for concurrent use.
+func DefaultSeedSource() SeedSource {
+	return cryptoSeedSource{}
+}
+
+// ShuffleCards shuffles the cards in place. The resulting order only depends on the seed, so shuffling
+// the same cards with the same seed always produces the same order.
+func ShuffleCards(cards []Card, seed int64) {
+	SeededShuffler{Seed: seed}.Shuffle(cards)
+}
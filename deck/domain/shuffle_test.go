@@ -0,0 +1,115 @@
+package domain_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+const (
+	statCards    = 8
+	statShuffles = 40000
+	// statCriticalValue is the chi-square critical value for (statCards-1)^2 = 49 degrees of freedom
+	// at a significance level of 0.00001, so an unbiased shuffle fails the test once every 100000 runs.
+	statCriticalValue = 103.4
+)
+
+// naiveShuffler swaps every card with any position, a well known biased shuffle used to check the power of the test.
+type naiveShuffler struct {
+	r *rand.Rand
+}
+
+func (s naiveShuffler) Shuffle(cards []domain.Card) {
+	for i := range cards {
+		j := s.r.Intn(len(cards))
+		cards[i], cards[j] = cards[j], cards[i]
+	}
+}
+
+// positionsChiSquare shuffles a sorted set of cards many times, counting the times every card lands in every
+// position, and returns the chi-square statistic of the counts against a uniform distribution.
+func positionsChiSquare(shuffler func(i int) domain.Shuffler) float64 {
+	sorted := domain.CompleteDeckCards()[:statCards]
+	index := make(map[domain.Card]int, statCards)
+
+	for i, c := range sorted {
+		index[c] = i
+	}
+
+	var counts [statCards][statCards]int
+
+	for i := 0; i < statShuffles; i++ {
+		cards := append([]domain.Card(nil), sorted...)
+		shuffler(i).Shuffle(cards)
+		for pos, c := range cards {
+			counts[index[c]][pos]++
+		}
+	}
+
+	expected := float64(statShuffles) / statCards
+	chiSquare := 0.0
+
+	for _, row := range counts {
+		for _, observed := range row {
+			d := float64(observed) - expected
+			chiSquare += d * d / expected
+		}
+	}
+
+	return chiSquare
+}
+
+func TestShufflers_AreUnbiased(t *testing.T) {
+	tests := []struct {
+		name     string
+		shuffler func(i int) domain.Shuffler
+	}{
+		{
+			name:     "seeded shuffle with consecutive seeds",
+			shuffler: func(i int) domain.Shuffler { return domain.SeededShuffler{Seed: int64(i)} },
+		},
+		{
+			name:     "secure shuffle",
+			shuffler: func(int) domain.Shuffler { return domain.SecureShuffler{} },
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := positionsChiSquare(tt.shuffler); got > statCriticalValue {
+				t.Errorf("chi-square of the positions = %.2f, want at most %.2f", got, statCriticalValue)
+			}
+		})
+	}
+	t.Run("detects a biased shuffle", func(t *testing.T) {
+		s := naiveShuffler{r: rand.New(rand.NewSource(1))}
+		if got := positionsChiSquare(func(int) domain.Shuffler { return s }); got <= statCriticalValue {
+			t.Errorf("chi-square of the positions = %.2f, want more than %.2f", got, statCriticalValue)
+		}
+	})
+}
+
+func TestParseShuffleStrategy(t *testing.T) {
+	for _, s := range []domain.ShuffleStrategy{domain.SeededShuffle, domain.SecureShuffle} {
+		if got, err := domain.ParseShuffleStrategy(string(s)); err != nil || got != s {
+			t.Errorf("ParseShuffleStrategy(%q) = %v, %v, want %v", s, got, err, s)
+		}
+	}
+	if _, err := domain.ParseShuffleStrategy("perfect"); err == nil {
+		t.Error("ParseShuffleStrategy() should fail with an unknown strategy")
+	}
+}
+
+func TestNewDeck_SecureShuffle(t *testing.T) {
+	got := domain.NewDeck(true, domain.CompleteDeckCards(), domain.WithShuffleStrategy(domain.SecureShuffle), domain.WithSeed(1))
+	if got.ShuffleStrategy != domain.SecureShuffle {
+		t.Errorf("New deck strategy = %q, want %q", got.ShuffleStrategy, domain.SecureShuffle)
+	}
+	if got.Seed != 0 {
+		t.Errorf("New deck seed = %d, want no seed", got.Seed)
+	}
+	if len(got.Cards) != 52 {
+		t.Errorf("New deck length = %d, want 52", len(got.Cards))
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MaxTags is the maximum number of tags of a deck.
+const MaxTags = 16
+
+var (
+	// ErrInvalidTag error returned when a tag is not valid or a deck is given too many tags.
+	ErrInvalidTag = errors.New("invalid_tag")
+)
+
+// ValidateTag returns an error if the tag isn't between 1 and 64 letters, digits, dashes or underscores.
+func ValidateTag(tag string) error {
+	if !nameRegexp.MatchString(tag) {
+		return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+	}
+
+	return nil
+}
+
+// SetTags replaces the tags of the deck, which are kept sorted and without duplicates.
+// Returns an error if a tag is invalid or if there are more than MaxTags tags.
+func (d *Deck) SetTags(tags []string) error {
+	unique := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		if err := ValidateTag(tag); err != nil {
+			return err
+		}
+
+		unique[tag] = true
+	}
+
+	if len(unique) > MaxTags {
+		return fmt.Errorf("%w: a deck can't have more than %d tags", ErrInvalidTag, MaxTags)
+	}
+
+	d.Tags = nil
+
+	for tag := range unique {
+		d.Tags = append(d.Tags, tag)
+	}
+
+	sort.Strings(d.Tags)
+
+	return nil
+}
+
+// HasTags returns whether the deck has every one of the given tags.
+func (d *Deck) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		i := sort.SearchStrings(d.Tags, tag)
+
+		if i == len(d.Tags) || d.Tags[i] != tag {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package domain_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestDeck_SetTags(t *testing.T) {
+	tooMany := make([]string, domain.MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag-%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr error
+	}{
+		{name: "sorts and removes duplicates", tags: []string{"poker", "table-1", "poker"}, want: []string{"poker", "table-1"}},
+		{name: "removes every tag", tags: nil, want: nil},
+		{name: "invalid tag", tags: []string{"poker", "two words"}, want: []string{"old"}, wantErr: domain.ErrInvalidTag},
+		{name: "too many tags", tags: tooMany, want: []string{"old"}, wantErr: domain.ErrInvalidTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &domain.Deck{Tags: []string{"old"}}
+			if err := d.SetTags(tt.tags); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Deck.SetTags() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.Tags, tt.want) {
+				t.Errorf("Deck.Tags = %v, want %v", d.Tags, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeck_HasTags(t *testing.T) {
+	d := &domain.Deck{Tags: []string{"blackjack", "table-1"}}
+	tests := []struct {
+		name string
+		tags []string
+		want bool
+	}{
+		{name: "no tags", want: true},
+		{name: "every tag", tags: []string{"table-1", "blackjack"}, want: true},
+		{name: "a missing tag", tags: []string{"blackjack", "table-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.HasTags(tt.tags); got != tt.want {
+				t.Errorf("Deck.HasTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultUndoDepth is the maximum number of operations that can be undone on a deck by default.
+	DefaultUndoDepth = 10
+	// MaxUndoDepth is the highest maximum number of operations that can be undone on a deck.
+	MaxUndoDepth = 100
+)
+
+var (
+	// ErrUndoDisabled error returned when undoing operations on a deck that doesn't allow it.
+	ErrUndoDisabled = errors.New("undo_disabled")
+	// ErrInvalidUndoDepth error returned when setting an undo depth outside of [0, MaxUndoDepth].
+	ErrInvalidUndoDepth = errors.New("invalid_undo_depth")
+	// ErrInvalidUndoSteps error returned when undoing less than one operation or more than the deck allows.
+	ErrInvalidUndoSteps = errors.New("invalid_undo_steps")
+)
+
+// SetUndoDepth sets the maximum number of operations that can be undone on the deck, 0 to disable undo.
+// Returns an error if the depth is negative or greater than MaxUndoDepth.
+func (d *Deck) SetUndoDepth(depth int) error {
+	if depth < 0 || depth > MaxUndoDepth {
+		return fmt.Errorf("%w: must be between 0 and %d", ErrInvalidUndoDepth, MaxUndoDepth)
+	}
+
+	d.UndoDepth = depth
+
+	return nil
+}
+
+// Undoable returns how many operations can currently be undone on the deck, given its history.
+// Operations can't be undone past the creation of the deck, nor past the last UndoDepth operations applied to it,
+// counting the ones already undone.
+func (d *Deck) Undoable(h History) int {
+	if d.UndoDepth == 0 {
+		return 0
+	}
+
+	stack := h.undoStack()
+	undoable := 0
+
+	for steps := 1; steps < len(stack); steps++ {
+		if h.operationsAfter(stack[len(stack)-1-steps]) > d.UndoDepth {
+			break
+		}
+
+		undoable = steps
+	}
+
+	return undoable
+}
+
+// Undo reverts the deck to its state before the last operations in effect, rebuilt from its history, and records an
+// UndoneEvent at the given time. Undoing again reverts the operations before the ones already undone. Returns an
+// error if the deck doesn't allow undo, if the steps are less than one or more than the operations that can be
+// undone, or if the state to revert to can't be rebuilt.
+func (d *Deck) Undo(h History, steps int, at time.Time, requestID, player string) error {
+	if d.UndoDepth == 0 {
+		return ErrUndoDisabled
+	}
+
+	if undoable := d.Undoable(h); steps < 1 || steps > undoable {
+		return fmt.Errorf("%w: must be between 1 and %d", ErrInvalidUndoSteps, undoable)
+	}
+
+	stack := h.undoStack()
+	target := h[stack[len(stack)-1-steps]]
+	state, err := h.StateAt(target.Number)
+
+	if err != nil {
+		return fmt.Errorf("rebuilding the state to revert to failed: %w", err)
+	}
+
+	version, lastEvent, pending := d.Version, d.LastEvent, d.Pending
+	*d = *state
+	d.Version, d.LastEvent, d.Pending = version, lastEvent, pending
+	d.Change = &EventParams{}
+
+	d.Record(UndoneEvent, at, requestID, player)
+	d.Pending[len(d.Pending)-1].RevertedTo = target.Number
+
+	return nil
+}
+
+// undoStack returns the indexes in the history of the events whose changes are in effect, in the order they happened.
+// The state of the deck is the state after the last of them.
+func (h History) undoStack() []int {
+	var stack []int
+
+	for i, e := range h {
+		if e.Type != UndoneEvent {
+			stack = append(stack, i)
+			continue
+		}
+
+		for len(stack) > 0 && h[stack[len(stack)-1]].Number != e.RevertedTo {
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return stack
+}
+
+// operationsAfter returns the number of operations recorded after the event at the given index of the history,
+// without counting undos.
+func (h History) operationsAfter(index int) int {
+	n := 0
+
+	for _, e := range h[index+1:] {
+		if e.Type != UndoneEvent {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,115 @@
+package domain_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// undoableDeck returns an unshuffled complete deck with the given undo depth, from which one card was drawn in each
+// of the given number of operations.
+func undoableDeck(t *testing.T, depth, draws int) *domain.Deck {
+	t.Helper()
+	d := domain.NewDeck(false, domain.CompleteDeckCards())
+	if err := d.SetUndoDepth(depth); err != nil {
+		t.Fatalf("Deck.SetUndoDepth() error = %v", err)
+	}
+	d.Record(domain.CreatedEvent, time.Time{}, "", "")
+	for i := 0; i < draws; i++ {
+		d.Draw(1)
+		d.Record(domain.DrawnEvent, time.Time{}, "", "")
+	}
+	return d
+}
+
+func TestDeck_SetUndoDepth(t *testing.T) {
+	for _, depth := range []int{-1, domain.MaxUndoDepth + 1} {
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := d.SetUndoDepth(depth); !errors.Is(err, domain.ErrInvalidUndoDepth) {
+			t.Errorf("Deck.SetUndoDepth(%d) error = %v, want %v", depth, err, domain.ErrInvalidUndoDepth)
+		}
+	}
+}
+
+func TestDeck_Undo(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("reverts the deck to its state before the last operations", func(t *testing.T) {
+		d := undoableDeck(t, 10, 3)
+		want, err := domain.History(d.Pending).StateAt(2)
+		if err != nil {
+			t.Fatalf("History.StateAt() error = %v", err)
+		}
+		if err := d.Undo(d.Pending, 2, at, "request-1", "alice"); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		if !reflect.DeepEqual(d.Cards, want.Cards) || !reflect.DeepEqual(d.Drawn, want.Drawn) {
+			t.Errorf("Deck.Undo() = %v, want %v", d, want)
+		}
+		e := d.Pending[len(d.Pending)-1]
+		if len(d.Pending) != 5 || e.Type != domain.UndoneEvent || e.RevertedTo != 2 || e.Player != "alice" {
+			t.Errorf("Deck.Undo() event = %+v, want an undone event reverting to the event 2", e)
+		}
+	})
+	t.Run("undoing again reverts the operations before the ones undone", func(t *testing.T) {
+		d := undoableDeck(t, 10, 3)
+		if err := d.Undo(d.Pending, 1, at, "", ""); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		if err := d.Undo(d.Pending, 1, at, "", ""); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		if got := d.Pending[len(d.Pending)-1].RevertedTo; got != 2 || len(d.Cards) != 51 {
+			t.Errorf("Deck.Undo() reverted to %d with %d cards, want 2 with 51", got, len(d.Cards))
+		}
+		if got := d.Undoable(d.Pending); got != 1 {
+			t.Errorf("Deck.Undoable() = %d, want 1", got)
+		}
+	})
+	t.Run("operations after an undo are undone first", func(t *testing.T) {
+		d := undoableDeck(t, 10, 2)
+		if err := d.Undo(d.Pending, 1, at, "", ""); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		d.Draw(5)
+		d.Record(domain.DrawnEvent, at, "", "")
+		if err := d.Undo(d.Pending, 1, at, "", ""); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		if got := d.Pending[len(d.Pending)-1].RevertedTo; got != 2 || len(d.Cards) != 51 {
+			t.Errorf("Deck.Undo() reverted to %d with %d cards, want 2 with 51", got, len(d.Cards))
+		}
+	})
+	t.Run("can't undo past the undo depth", func(t *testing.T) {
+		d := undoableDeck(t, 2, 4)
+		if got := d.Undoable(d.Pending); got != 2 {
+			t.Errorf("Deck.Undoable() = %d, want 2", got)
+		}
+		if err := d.Undo(d.Pending, 2, at, "", ""); err != nil {
+			t.Fatalf("Deck.Undo() error = %v", err)
+		}
+		if err := d.Undo(d.Pending, 1, at, "", ""); !errors.Is(err, domain.ErrInvalidUndoSteps) {
+			t.Errorf("Deck.Undo() error = %v, want %v", err, domain.ErrInvalidUndoSteps)
+		}
+	})
+	t.Run("can't undo the creation of the deck", func(t *testing.T) {
+		d := undoableDeck(t, 10, 1)
+		for _, steps := range []int{0, 2} {
+			if err := d.Undo(d.Pending, steps, at, "", ""); !errors.Is(err, domain.ErrInvalidUndoSteps) {
+				t.Errorf("Deck.Undo(%d) error = %v, want %v", steps, err, domain.ErrInvalidUndoSteps)
+			}
+		}
+	})
+	t.Run("returns an error if undo is disabled", func(t *testing.T) {
+		d := undoableDeck(t, 0, 1)
+		if err := d.Undo(d.Pending, 1, at, "", ""); !errors.Is(err, domain.ErrUndoDisabled) {
+			t.Errorf("Deck.Undo() error = %v, want %v", err, domain.ErrUndoDisabled)
+		}
+		if got := d.Undoable(d.Pending); got != 0 {
+			t.Errorf("Deck.Undoable() = %d, want 0", got)
+		}
+	})
+}
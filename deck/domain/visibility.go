@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidVisibility error returned when a visibility is unknown or doesn't list valid players.
+	ErrInvalidVisibility = errors.New("invalid_visibility")
+)
+
+// VisibilityMode names who can see the cards of a part of a deck.
+type VisibilityMode string
+
+const (
+	// FaceUp cards can be seen by anyone.
+	FaceUp VisibilityMode = "face_up"
+	// FaceDown cards can't be seen by anyone, only their amount.
+	FaceDown VisibilityMode = "face_down"
+	// VisibleToPlayers cards can only be seen by the players listed.
+	VisibleToPlayers VisibilityMode = "players"
+)
+
+// Visibility determines who can see the cards of a part of a deck, such as its remaining cards, a pile or a hand.
+// The zero value means the default visibility of the part.
+type Visibility struct {
+	Mode VisibilityMode `json:"mode,omitempty"`
+	// Players that can see the cards. Only set with the VisibleToPlayers mode.
+	Players []string `json:"players,omitempty"`
+}
+
+// Validate returns an error if the mode is unknown, or if the players aren't valid for the mode.
+func (v Visibility) Validate() error {
+	switch v.Mode {
+	case FaceUp, FaceDown:
+		if len(v.Players) > 0 {
+			return fmt.Errorf("%w: players can only be given with the %q mode", ErrInvalidVisibility, VisibleToPlayers)
+		}
+	case VisibleToPlayers:
+		if len(v.Players) == 0 {
+			return fmt.Errorf("%w: at least one player must be given", ErrInvalidVisibility)
+		}
+
+		for _, p := range v.Players {
+			if err := ValidatePlayerName(p); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidVisibility, err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidVisibility, v.Mode)
+	}
+
+	return nil
+}
+
+// VisibleTo returns whether the given player can see the cards. An empty player stands for anyone not playing,
+// who can only see face up cards.
+func (v Visibility) VisibleTo(player string) bool {
+	switch v.Mode {
+	case FaceUp:
+		return true
+	case VisibleToPlayers:
+		for _, p := range v.Players {
+			if p != "" && p == player {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (v Visibility) clone() Visibility {
+	v.Players = append([]string(nil), v.Players...)
+	return v
+}
+
+// or returns the visibility, or the given default one if it isn't set.
+func (v Visibility) or(def Visibility) Visibility {
+	if v.Mode == "" {
+		return def
+	}
+
+	return v
+}
+
+// EffectiveVisibility returns the visibility of the remaining cards of the deck, face up by default.
+func (d *Deck) EffectiveVisibility() Visibility {
+	return d.Visibility.or(Visibility{Mode: FaceUp})
+}
+
+// EffectiveVisibility returns the visibility of the pile, face up by default.
+func (p *Pile) EffectiveVisibility() Visibility {
+	return p.Visibility.or(Visibility{Mode: FaceUp})
+}
+
+// EffectiveVisibility returns the visibility of the hand, only visible to its player by default.
+func (h *Hand) EffectiveVisibility() Visibility {
+	return h.Visibility.or(Visibility{Mode: VisibleToPlayers, Players: []string{h.Player}})
+}
+
+// CopyVisibility sets the visibility of the remaining cards of the deck, and of its piles and hands, to the one of the
+// same parts of the given deck. The piles and hands the given deck doesn't have keep their visibility.
+func (d *Deck) CopyVisibility(from *Deck) {
+	d.Visibility = from.Visibility.clone()
+
+	for _, p := range d.Piles {
+		if fp, err := from.Pile(p.Name); err == nil {
+			p.Visibility = fp.Visibility.clone()
+		}
+	}
+
+	for _, h := range d.Hands {
+		if fh, err := from.Hand(h.Player); err == nil {
+			h.Visibility = fh.Visibility.clone()
+		}
+	}
+}
+
+// SetVisibility sets the visibility of the remaining cards of the deck. Returns an error if the visibility is invalid.
+func (d *Deck) SetVisibility(v Visibility) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+
+	d.Visibility = v.clone()
+	d.Change = &EventParams{Visibility: visibilityParam(v)}
+
+	return nil
+}
+
+// SetPileVisibility sets the visibility of the pile. Returns an error if the visibility is invalid or if the deck
+// doesn't have the pile.
+func (d *Deck) SetPileVisibility(name string, v Visibility) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+
+	p, err := d.Pile(name)
+
+	if err != nil {
+		return err
+	}
+
+	p.Visibility = v.clone()
+	d.Change = &EventParams{Pile: name, Visibility: visibilityParam(v)}
+
+	return nil
+}
+
+// SetHandVisibility sets the visibility of the hand of the player. Returns an error if the visibility is invalid
+// or if the player doesn't have a hand in the deck.
+func (d *Deck) SetHandVisibility(player string, v Visibility) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+
+	h, err := d.Hand(player)
+
+	if err != nil {
+		return err
+	}
+
+	h.Visibility = v.clone()
+	d.Change = &EventParams{Hand: player, Visibility: visibilityParam(v)}
+
+	return nil
+}
+
+// visibilityParam returns a copy of the visibility to record as the parameter of an event.
+func visibilityParam(v Visibility) *Visibility {
+	c := v.clone()
+	return &c
+}
+
+// replayVisibility sets again the visibility recorded in the parameters, of the pile or the hand if they name one.
+func (d *Deck) replayVisibility(p *EventParams) error {
+	if p.Visibility == nil {
+		return errors.New("the event doesn't have a visibility")
+	}
+
+	switch {
+	case p.Pile != "":
+		return d.SetPileVisibility(p.Pile, *p.Visibility)
+	case p.Hand != "":
+		return d.SetHandVisibility(p.Hand, *p.Visibility)
+	default:
+		return d.SetVisibility(*p.Visibility)
+	}
+}
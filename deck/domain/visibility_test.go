@@ -0,0 +1,85 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+func TestVisibility_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		visibility domain.Visibility
+		wantErr    error
+	}{
+		{name: "accepts face up", visibility: domain.Visibility{Mode: domain.FaceUp}},
+		{name: "accepts face down", visibility: domain.Visibility{Mode: domain.FaceDown}},
+		{name: "accepts players", visibility: domain.Visibility{Mode: domain.VisibleToPlayers, Players: []string{"alice"}}},
+		{name: "fails with an unknown mode", visibility: domain.Visibility{Mode: "sideways"}, wantErr: domain.ErrInvalidVisibility},
+		{name: "fails without mode", visibility: domain.Visibility{}, wantErr: domain.ErrInvalidVisibility},
+		{name: "fails with players without players mode", visibility: domain.Visibility{Mode: domain.FaceUp, Players: []string{"alice"}}, wantErr: domain.ErrInvalidVisibility},
+		{name: "fails without players in players mode", visibility: domain.Visibility{Mode: domain.VisibleToPlayers}, wantErr: domain.ErrInvalidVisibility},
+		{name: "fails with invalid player names", visibility: domain.Visibility{Mode: domain.VisibleToPlayers, Players: []string{"a,b"}}, wantErr: domain.ErrInvalidVisibility},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.visibility.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Visibility.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVisibility_VisibleTo(t *testing.T) {
+	players := domain.Visibility{Mode: domain.VisibleToPlayers, Players: []string{"alice"}}
+	tests := []struct {
+		name       string
+		visibility domain.Visibility
+		player     string
+		want       bool
+	}{
+		{name: "face up cards are visible to anyone", visibility: domain.Visibility{Mode: domain.FaceUp}, want: true},
+		{name: "face down cards are visible to no one", visibility: domain.Visibility{Mode: domain.FaceDown}, player: "alice"},
+		{name: "cards are visible to the players listed", visibility: players, player: "alice", want: true},
+		{name: "cards aren't visible to other players", visibility: players, player: "bob"},
+		{name: "cards aren't visible to anyone not playing", visibility: players},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.visibility.VisibleTo(tt.player); got != tt.want {
+				t.Errorf("Visibility.VisibleTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveVisibility_Defaults(t *testing.T) {
+	d := &domain.Deck{}
+	if !d.EffectiveVisibility().VisibleTo("") {
+		t.Error("Deck.EffectiveVisibility() should be face up by default")
+	}
+	p := &domain.Pile{Name: "discard"}
+	if !p.EffectiveVisibility().VisibleTo("") {
+		t.Error("Pile.EffectiveVisibility() should be face up by default")
+	}
+	h := &domain.Hand{Player: "alice"}
+	if !h.EffectiveVisibility().VisibleTo("alice") || h.EffectiveVisibility().VisibleTo("bob") {
+		t.Error("Hand.EffectiveVisibility() should only be visible to its player by default")
+	}
+}
+
+func TestDeck_SetHandVisibility(t *testing.T) {
+	d := &domain.Deck{Hands: []*domain.Hand{{Player: "alice"}}}
+	if err := d.SetHandVisibility("alice", domain.Visibility{Mode: domain.FaceUp}); err != nil {
+		t.Fatalf("Deck.SetHandVisibility() error = %v", err)
+	}
+	if !d.Hands[0].EffectiveVisibility().VisibleTo("bob") {
+		t.Error("Deck.SetHandVisibility() didn't make the hand face up")
+	}
+	if err := d.SetHandVisibility("bob", domain.Visibility{Mode: domain.FaceUp}); !errors.Is(err, domain.ErrHandNotFound) {
+		t.Errorf("Deck.SetHandVisibility() error = %v, want %v", err, domain.ErrHandNotFound)
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const bearerPrefix = "Bearer "
+
+// AdminMiddleware returns a middleware only letting through the requests authenticated with the given admin token,
+// given as a bearer token in the Authorization header. Answers with a 401 status otherwise.
+func AdminMiddleware(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			given, ok := strings.CutPrefix(auth, bearerPrefix)
+
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return c.JSON(http.StatusUnauthorized, buildErrorMap("The request requires the admin token"))
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/handler"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "lets the admin token through", authorization: "Bearer admin-token", want: http.StatusOK},
+		{name: "rejects requests without a token", want: http.StatusUnauthorized},
+		{name: "rejects another token", authorization: "Bearer other-token", want: http.StatusUnauthorized},
+		{name: "rejects the token without the bearer scheme", authorization: "admin-token", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/v1/decks", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, handler.AdminMiddleware("admin-token"))
+			req := httptest.NewRequest(http.MethodGet, "/v1/decks", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cfagudelo96/toggle-test/deck/domain"
 	"github.com/cfagudelo96/toggle-test/deck/service"
@@ -13,16 +16,55 @@ import (
 )
 
 const (
-	uuidParam          = "uuid"
-	shuffledQueryParam = "shuffled"
-	cardsQueryParam    = "cards"
+	uuidParam              = "uuid"
+	shuffledQueryParam     = "shuffled"
+	cardsQueryParam        = "cards"
+	seedQueryParam         = "seed"
+	fairQueryParam         = "fair"
+	clientSeedQueryParam   = "client_seed"
+	shuffleQueryParam      = "shuffle"
+	decksQueryParam        = "decks"
+	penetrationQueryParam  = "penetration"
+	jokersQueryParam       = "jokers"
+	suitsQueryParam        = "suits"
+	ranksQueryParam        = "ranks"
+	excludeRanksQueryParam = "exclude_ranks"
+	countQueryParam        = "count"
+	fromQueryParam         = "from"
+	visibilityQueryParam   = "visibility"
+	undoDepthQueryParam    = "undo_depth"
+	ttlQueryParam          = "ttl"
+	tagsQueryParam         = "tags"
 )
 
 // DeckService represents the interface required to handle the decks use cases.
 type DeckService interface {
 	CreateDeck(ctx context.Context, opts ...service.DeckCreationOption) (service.CreateDeckOutput, error)
 	OpenDeck(ctx context.Context, uuid string) (service.OpenDeckOutput, error)
-	DrawCards(ctx context.Context, uuid string, amount int) (service.DrawCardsOutput, error)
+	DrawCards(ctx context.Context, uuid string, amount int, opts ...service.DrawOption) (service.DrawCardsOutput, error)
+	RevealDeck(ctx context.Context, uuid string) (service.RevealDeckOutput, error)
+	AddToPile(ctx context.Context, uuid, pile string, cards []domain.Card) (service.PileOutput, error)
+	ListPile(ctx context.Context, uuid, pile string) (service.PileOutput, error)
+	DrawFromPile(ctx context.Context, uuid, pile string, amount int) (service.DrawCardsOutput, error)
+	ShufflePile(ctx context.Context, uuid, pile string) (service.PileOutput, error)
+	ReturnCards(ctx context.Context, uuid string, cards []domain.Card, position domain.Position) (service.OpenDeckOutput, error)
+	ReshuffleDeck(ctx context.Context, uuid string) (service.OpenDeckOutput, error)
+	ResetDeck(ctx context.Context, uuid string) (service.OpenDeckOutput, error)
+	PeekCards(ctx context.Context, uuid string, amount int, position domain.Position) (service.PeekCardsOutput, error)
+	CutDeck(ctx context.Context, uuid string, index *int) (service.OperationOutput, error)
+	RiffleShuffle(ctx context.Context, uuid string, times int) (service.OperationOutput, error)
+	OverhandShuffle(ctx context.Context, uuid string, times int) (service.OperationOutput, error)
+	DealCards(ctx context.Context, uuid string, players []string, cardsPerPlayer int) (service.DealCardsOutput, error)
+	ListHand(ctx context.Context, uuid, player string) (service.HandOutput, error)
+	SetDeckVisibility(ctx context.Context, uuid string, v domain.Visibility) (service.VisibilityOutput, error)
+	SetPileVisibility(ctx context.Context, uuid, pile string, v domain.Visibility) (service.VisibilityOutput, error)
+	SetHandVisibility(ctx context.Context, uuid, player string, v domain.Visibility) (service.VisibilityOutput, error)
+	History(ctx context.Context, uuid string) (service.HistoryOutput, error)
+	StateAt(ctx context.Context, uuid string, number int) (service.StateAtOutput, error)
+	UndoDeck(ctx context.Context, uuid string, steps int) (service.UndoOutput, error)
+	CloseDeck(ctx context.Context, uuid string) (service.OpenDeckOutput, error)
+	DeleteDeck(ctx context.Context, uuid string) error
+	ListDecks(ctx context.Context, filter service.DeckFilter, cursor string, limit int) (service.ListDecksOutput, error)
 }
 
 // DeckEchoHandler handles the echo HTTP requests.
@@ -39,6 +81,25 @@ func NewDeckEchoHandler(s DeckService) *DeckEchoHandler {
 
 // HandleCreateDeck handles the endpoint to create a new deck.
 func (h *DeckEchoHandler) HandleCreateDeck(c echo.Context) error {
+	opts, err := createDeckOptions(c)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.CreateDeck(c.Request().Context(), opts...)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// createDeckOptions parses the query parameters of the endpoint to create a new deck.
+func createDeckOptions(c echo.Context) ([]service.DeckCreationOption, error) {
 	var opts []service.DeckCreationOption
 
 	if c.QueryParam(shuffledQueryParam) == "n" {
@@ -46,23 +107,169 @@ func (h *DeckEchoHandler) HandleCreateDeck(c echo.Context) error {
 	}
 
 	if cardsStr := c.QueryParam(cardsQueryParam); cardsStr != "" {
-		cardsSplit := strings.Split(cardsStr, ",")
-		cards := make([]domain.Card, len(cardsSplit))
+		cards, err := domain.FromCodes(strings.Split(cardsStr, ","))
 
-		for i, cardCode := range cardsSplit {
-			cards[i] = domain.FromCode(cardCode)
+		if err != nil {
+			return nil, err
 		}
 
 		opts = append(opts, service.WithCards(cards))
 	}
 
-	res, err := h.deckService.CreateDeck(c.Request().Context(), opts...)
+	compositionOpts, err := compositionOptions(c)
 
 	if err != nil {
-		return mapError(c, err)
+		return nil, err
 	}
 
-	return c.JSON(http.StatusCreated, res)
+	opts = append(opts, compositionOpts...)
+
+	if strategyStr := c.QueryParam(shuffleQueryParam); strategyStr != "" {
+		strategy, err := domain.ParseShuffleStrategy(strategyStr)
+
+		if err != nil {
+			return nil, badRequestError("Invalid shuffle, must be seeded or secure")
+		}
+
+		opts = append(opts, service.WithShuffleStrategy(strategy))
+	}
+
+	if seedStr := c.QueryParam(seedQueryParam); seedStr != "" {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+
+		if err != nil {
+			return nil, badRequestError("Invalid seed, must be an integer")
+		}
+
+		opts = append(opts, service.WithSeed(seed))
+	}
+
+	if visibilityStr := c.QueryParam(visibilityQueryParam); visibilityStr != "" {
+		mode := domain.VisibilityMode(visibilityStr)
+
+		if mode != domain.FaceUp && mode != domain.FaceDown {
+			return nil, badRequestError("Invalid visibility, must be face_up or face_down")
+		}
+
+		opts = append(opts, service.WithVisibility(domain.Visibility{Mode: mode}))
+	}
+
+	if ttlStr := c.QueryParam(ttlQueryParam); ttlStr != "" {
+		ttl, err := time.ParseDuration(ttlStr)
+
+		if err != nil || ttl <= 0 {
+			return nil, badRequestError("Invalid ttl, must be a positive duration such as 30m or 2h")
+		}
+
+		opts = append(opts, service.WithTTL(ttl))
+	}
+
+	if tagsStr := c.QueryParam(tagsQueryParam); tagsStr != "" {
+		opts = append(opts, service.WithTags(strings.Split(tagsStr, ",")...))
+	}
+
+	if undoDepthStr := c.QueryParam(undoDepthQueryParam); undoDepthStr != "" {
+		undoDepth, err := strconv.Atoi(undoDepthStr)
+
+		if err != nil || undoDepth < 0 || undoDepth > domain.MaxUndoDepth {
+			return nil, badRequestError(fmt.Sprintf("Invalid undo depth, must be between 0 and %d", domain.MaxUndoDepth))
+		}
+
+		opts = append(opts, service.WithUndoDepth(undoDepth))
+	}
+
+	if clientSeed := c.QueryParam(clientSeedQueryParam); clientSeed != "" || c.QueryParam(fairQueryParam) == "y" {
+		opts = append(opts, service.ProvablyFair(clientSeed))
+	}
+
+	return opts, nil
+}
+
+// compositionOptions parses the query parameters describing the composition of a new deck.
+func compositionOptions(c echo.Context) ([]service.DeckCreationOption, error) {
+	var opts []service.DeckCreationOption
+
+	if decksStr := c.QueryParam(decksQueryParam); decksStr != "" {
+		decks, err := strconv.Atoi(decksStr)
+
+		if err != nil || decks < 1 || decks > domain.MaxShoeDecks {
+			return nil, badRequestError(fmt.Sprintf("Invalid decks, must be between 1 and %d", domain.MaxShoeDecks))
+		}
+
+		opts = append(opts, service.WithDecks(decks))
+	}
+
+	if penetrationStr := c.QueryParam(penetrationQueryParam); penetrationStr != "" {
+		penetration, err := strconv.ParseFloat(penetrationStr, 64)
+
+		if err != nil || penetration <= 0 || penetration >= 1 {
+			return nil, badRequestError("Invalid penetration, must be between 0 and 1")
+		}
+
+		opts = append(opts, service.WithPenetration(penetration))
+	}
+
+	if jokersStr := c.QueryParam(jokersQueryParam); jokersStr != "" {
+		jokers, err := strconv.Atoi(jokersStr)
+
+		if err != nil || jokers < 0 || jokers > domain.MaxJokers {
+			return nil, fmt.Errorf("%w: invalid jokers, must be between 0 and %d", domain.ErrInvalidComposition,
+				domain.MaxJokers)
+		}
+
+		opts = append(opts, service.WithJokers(jokers))
+	}
+
+	if suitsStr := c.QueryParam(suitsQueryParam); suitsStr != "" {
+		var suits []domain.Suit
+
+		for _, code := range strings.Split(suitsStr, ",") {
+			suit, err := domain.ParseSuit(strings.ToUpper(code))
+
+			if err != nil {
+				return nil, badRequestError("Invalid suits, must be a list of C, D, H or S")
+			}
+
+			suits = append(suits, suit)
+		}
+
+		opts = append(opts, service.WithSuits(suits...))
+	}
+
+	if ranksStr := c.QueryParam(ranksQueryParam); ranksStr != "" {
+		bounds := strings.Split(strings.ToUpper(ranksStr), "-")
+
+		if len(bounds) != 2 {
+			return nil, badRequestError("Invalid ranks, must be a range such as 7-A")
+		}
+
+		lowest, lowestErr := domain.ParseRank(bounds[0])
+		highest, highestErr := domain.ParseRank(bounds[1])
+
+		if lowestErr != nil || highestErr != nil {
+			return nil, badRequestError("Invalid ranks, must be a range such as 7-A")
+		}
+
+		opts = append(opts, service.WithRankRange(lowest, highest))
+	}
+
+	if excludedStr := c.QueryParam(excludeRanksQueryParam); excludedStr != "" {
+		var ranks []domain.Rank
+
+		for _, code := range strings.Split(excludedStr, ",") {
+			rank, err := domain.ParseRank(strings.ToUpper(code))
+
+			if err != nil {
+				return nil, badRequestError("Invalid excluded ranks, must be a list of rank codes")
+			}
+
+			ranks = append(ranks, rank)
+		}
+
+		opts = append(opts, service.WithoutRanks(ranks...))
+	}
+
+	return opts, nil
 }
 
 // HandleOpenDeck handles the endpoint for opening a deck.
@@ -75,11 +282,120 @@ func (h *DeckEchoHandler) HandleOpenDeck(c echo.Context) error {
 		return mapError(c, err)
 	}
 
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleCloseDeck handles the endpoint for closing a deck, so it can't be modified anymore.
+func (h *DeckEchoHandler) HandleCloseDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	res, err := h.deckService.CloseDeck(c.Request().Context(), uuid)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleDeleteDeck handles the endpoint for deleting a deck.
+func (h *DeckEchoHandler) HandleDeleteDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	if err := h.deckService.DeleteDeck(c.Request().Context(), uuid); err != nil {
+		return mapError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// HandleRevealDeck handles the endpoint for revealing the secrets of a provably fair deck.
+func (h *DeckEchoHandler) HandleRevealDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	res, err := h.deckService.RevealDeck(c.Request().Context(), uuid)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandlePeekCards handles the endpoint for peeking at the top or bottom cards of a deck without drawing them.
+func (h *DeckEchoHandler) HandlePeekCards(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	count := 1
+
+	if countStr := c.QueryParam(countQueryParam); countStr != "" {
+		n, err := strconv.Atoi(countStr)
+
+		if err != nil || n < 0 {
+			return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid count, must be greater or equal to 0"))
+		}
+
+		count = n
+	}
+
+	position := domain.PositionTop
+
+	if from := c.QueryParam(fromQueryParam); from != "" {
+		position = domain.Position(from)
+
+		if position != domain.PositionTop && position != domain.PositionBottom {
+			return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid from, must be top or bottom"))
+		}
+	}
+
+	res, err := h.deckService.PeekCards(c.Request().Context(), uuid, count, position)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
 	return c.JSON(http.StatusOK, res)
 }
 
 type drawCardsRequest struct {
-	Amount int `json:"amount"`
+	Amount int      `json:"amount"`
+	From   string   `json:"from"`
+	Cards  []string `json:"cards"`
+}
+
+// drawOptions parses the optional fields of the body of the endpoint for drawing cards.
+func drawOptions(req drawCardsRequest) ([]service.DrawOption, error) {
+	if req.Cards != nil {
+		if req.From != "" {
+			return nil, badRequestError("Invalid body, from can't be combined with cards")
+		}
+
+		cards, err := domain.FromCodes(req.Cards)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []service.DrawOption{service.DrawSpecific(cards)}, nil
+	}
+
+	if req.From == "" {
+		return nil, nil
+	}
+
+	position, err := domain.ParsePosition(req.From)
+
+	if err != nil {
+		return nil, badRequestError("Invalid from, must be top, bottom or random")
+	}
+
+	return []service.DrawOption{service.DrawFrom(position)}, nil
 }
 
 // HandleDrawCars handles the endpoint for drawing cards from a deck.
@@ -96,24 +412,227 @@ func (h *DeckEchoHandler) HandleDrawCars(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid amount, must be greater or equal to 0"))
 	}
 
-	res, err := h.deckService.DrawCards(c.Request().Context(), uuid, req.Amount)
+	opts, err := drawOptions(req)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.DrawCards(c.Request().Context(), uuid, req.Amount, opts...)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type returnCardsRequest struct {
+	Cards    []string `json:"cards"`
+	Position string   `json:"position"`
+}
+
+type dealCardsRequest struct {
+	Players        []string `json:"players"`
+	CardsPerPlayer int      `json:"cards_per_player"`
+}
+
+// HandleDealCards handles the endpoint for dealing cards from a deck to several players.
+func (h *DeckEchoHandler) HandleDealCards(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := dealCardsRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	if len(req.Players) == 0 {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid players, at least one must be given"))
+	}
+
+	if req.CardsPerPlayer < 1 {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid cards per player, must be greater than 0"))
+	}
+
+	res, err := h.deckService.DealCards(c.Request().Context(), uuid, req.Players, req.CardsPerPlayer)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleReturnCards handles the endpoint for returning drawn cards to a deck.
+func (h *DeckEchoHandler) HandleReturnCards(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := returnCardsRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	position := domain.PositionTop
+
+	if req.Position != "" {
+		p, err := domain.ParsePosition(req.Position)
+
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid position, must be top, bottom or random"))
+		}
+
+		position = p
+	}
+
+	cards, err := domain.FromCodes(req.Cards)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.ReturnCards(c.Request().Context(), uuid, cards, position)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleReshuffleDeck handles the endpoint for reshuffling the cards remaining in a deck.
+func (h *DeckEchoHandler) HandleReshuffleDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	res, err := h.deckService.ReshuffleDeck(c.Request().Context(), uuid)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleResetDeck handles the endpoint for resetting a deck to its original composition.
+func (h *DeckEchoHandler) HandleResetDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	res, err := h.deckService.ResetDeck(c.Request().Context(), uuid)
 
 	if err != nil {
 		return mapError(c, err)
 	}
 
+	setETag(c, res.Version)
+
 	return c.JSON(http.StatusOK, res)
 }
 
+// badRequestError represents an invalid request, answered with a 400 status and the error as message.
+type badRequestError string
+
+func (e badRequestError) Error() string {
+	return string(e)
+}
+
 func mapError(c echo.Context, err error) error {
+	var (
+		invalidCardsErr   *domain.InvalidCardsError
+		cardsNotDrawnErr  *domain.CardsNotDrawnError
+		cardsNotInDeckErr *domain.CardsNotInDeckError
+		badRequestErr     badRequestError
+	)
+
 	switch {
+	case errors.As(err, &badRequestErr):
+		return c.JSON(http.StatusBadRequest, buildErrorMap(badRequestErr.Error()))
 	case errors.Is(err, domain.ErrDeckNotFound):
 		return c.JSON(http.StatusBadRequest, buildErrorMap("The deck given wasn't found"))
+	case errors.Is(err, domain.ErrDeckExpired):
+		return c.JSON(http.StatusGone, buildErrorMap("The deck given expired"))
+	case errors.Is(err, domain.ErrPileNotFound):
+		return c.JSON(http.StatusBadRequest, buildErrorMap("The pile given wasn't found"))
+	case errors.Is(err, domain.ErrHandNotFound):
+		return c.JSON(http.StatusBadRequest, buildErrorMap("The player given doesn't have a hand in the deck"))
+	case errors.Is(err, domain.ErrEventNotFound):
+		return c.JSON(http.StatusBadRequest, buildErrorMap("The event given wasn't found"))
+	case errors.Is(err, domain.ErrInvalidPileName):
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid pile name, must have up to 64 letters, digits, - or _"))
+	case errors.As(err, &cardsNotDrawnErr):
+		return c.JSON(http.StatusUnprocessableEntity, buildErrorMap(
+			fmt.Sprintf("The cards %s weren't drawn from the deck or are already in a pile",
+				strings.Join(cardsNotDrawnErr.Codes, ","))))
+	case errors.As(err, &cardsNotInDeckErr):
+		return c.JSON(http.StatusUnprocessableEntity, buildErrorMap(
+			fmt.Sprintf("The cards %s aren't in the deck", strings.Join(cardsNotInDeckErr.Codes, ","))))
+	case errors.Is(err, domain.ErrNotEnoughCards):
+		return c.JSON(http.StatusUnprocessableEntity, buildErrorMap("The deck doesn't have enough cards"))
+	case errors.Is(err, domain.ErrDeckNotFair):
+		return c.JSON(http.StatusBadRequest, buildErrorMap("The deck given isn't provably fair"))
+	case errors.Is(err, domain.ErrFairDeckReorder):
+		return c.JSON(http.StatusConflict, buildErrorMap("The deck is provably fair and can't be reordered"))
+	case errors.Is(err, domain.ErrDeckNotRevealable):
+		return c.JSON(http.StatusConflict, buildErrorMap("The deck can't be revealed until it is exhausted or closed"))
+	case errors.Is(err, service.ErrVersionMismatch):
+		return c.JSON(http.StatusPreconditionFailed, buildErrorMap("The deck isn't at the version given in "+IfMatchHeader))
+	case errors.Is(err, domain.ErrVersionConflict):
+		return c.JSON(http.StatusConflict, buildErrorMap("The deck was modified concurrently, retry the request"))
+	case errors.Is(err, domain.ErrDeckClosed):
+		return c.JSON(http.StatusConflict, buildErrorMap("The deck is closed and can't be modified"))
+	case errors.Is(err, service.ErrVisibilityNotAllowed):
+		return c.JSON(http.StatusForbidden, buildErrorMap(
+			"Only the owner of the deck, or the player of a hand, can change its visibility"))
+	case errors.Is(err, domain.ErrUndoDisabled):
+		return c.JSON(http.StatusConflict, buildErrorMap("The deck doesn't allow undoing operations"))
+	case errors.Is(err, service.ErrInvalidOption), errors.Is(err, domain.ErrInvalidComposition),
+		errors.Is(err, domain.ErrInvalidPenetration), errors.Is(err, domain.ErrInvalidCutIndex),
+		errors.Is(err, domain.ErrInvalidShuffleTimes), errors.Is(err, domain.ErrInvalidDeal),
+		errors.Is(err, domain.ErrInvalidPlayerName), errors.Is(err, domain.ErrInvalidVisibility),
+		errors.Is(err, domain.ErrInvalidUndoDepth), errors.Is(err, domain.ErrInvalidUndoSteps),
+		errors.Is(err, domain.ErrInvalidTTL), errors.Is(err, domain.ErrInvalidTag),
+		errors.Is(err, domain.ErrInvalidAmount), errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidLimit):
+		return c.JSON(http.StatusBadRequest, buildErrorMap(err.Error()))
+	case errors.As(err, &invalidCardsErr):
+		return c.JSON(http.StatusUnprocessableEntity, buildInvalidCardsResponse(invalidCardsErr))
 	default:
 		return err
 	}
 }
 
+type invalidCardCode struct {
+	Position int    `json:"position"`
+	Code     string `json:"code"`
+	Reason   string `json:"reason"`
+}
+
+type invalidCardsResponse struct {
+	Message string            `json:"message"`
+	Errors  []invalidCardCode `json:"errors"`
+}
+
+func buildInvalidCardsResponse(err *domain.InvalidCardsError) invalidCardsResponse {
+	res := invalidCardsResponse{
+		Message: "Some of the card codes given are invalid",
+		Errors:  make([]invalidCardCode, len(err.Errors)),
+	}
+
+	for i, e := range err.Errors {
+		res.Errors[i] = invalidCardCode{Position: e.Position, Code: e.Code, Reason: e.Reason}
+	}
+
+	return res
+}
+
 func buildErrorMap(message string) map[string]string {
 	return map[string]string{
 		"message": message,
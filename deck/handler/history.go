@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	eventNumberParam = "number"
+)
+
+// RequestIDMiddleware sets the ID of the request in its context, so the services record it in the events caused by
+// the request. The ID is taken from the response header set by the echo RequestID middleware, which must run before.
+func RequestIDMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+
+		if requestID != "" {
+			req := c.Request()
+			c.SetRequest(req.WithContext(service.ContextWithRequestID(req.Context(), requestID)))
+		}
+
+		return next(c)
+	}
+}
+
+// HandleHistory handles the endpoint for listing the events of a deck.
+func (h *DeckEchoHandler) HandleHistory(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	res, err := h.deckService.History(c.Request().Context(), uuid)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleStateAt handles the endpoint for rebuilding the state of a deck right after one of its events.
+func (h *DeckEchoHandler) HandleStateAt(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	number, err := strconv.Atoi(c.Param(eventNumberParam))
+
+	if err != nil || number < 1 {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid event number, must be greater than 0"))
+	}
+
+	res, err := h.deckService.StateAt(c.Request().Context(), uuid, number)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
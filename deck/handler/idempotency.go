@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// IdempotencyKeyHeader is the header holding the key a client gives to a request, so retrying it with the same
+	// key replays the first response instead of repeating the request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is the header set to true in the responses replayed for an idempotency key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the headers of a response stored for an idempotency key and set again when it is replayed.
+var replayedHeaders = []string{echo.HeaderContentType, ETagHeader}
+
+// IdempotencyMiddleware returns a middleware storing the successful responses of the requests with an
+// IdempotencyKeyHeader header in the given store for the given ttl, per key and deck. Retrying a request with the
+// same key replays its response, and reusing the key with a different request answers with a 422 status.
+// Requests that don't succeed or whose handler panics aren't stored, so they can be retried.
+func IdempotencyMiddleware(store service.IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid "+IdempotencyKeyHeader+" header, must have up to 255 characters"))
+			}
+
+			fingerprint, err := requestFingerprint(c)
+
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+			}
+
+			// The response must be stored even if the client goes away, that is when it is retried.
+			ctx := context.WithoutCancel(c.Request().Context())
+			k := service.IdempotencyKey{DeckID: c.Param(uuidParam), Key: key}
+
+			rec, reserved, err := store.Reserve(ctx, k, fingerprint, time.Now(), ttl)
+
+			if err != nil {
+				return err
+			}
+
+			if !reserved {
+				return replay(c, rec, fingerprint)
+			}
+
+			release := func() {
+				if err := store.Release(ctx, k); err != nil {
+					c.Logger().Errorf("releasing the idempotency key failed: %v", err)
+				}
+			}
+
+			// A panicking handler would leave the key reserved, answering every retry as in progress.
+			defer func() {
+				if r := recover(); r != nil {
+					release()
+					panic(r)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			err = next(c)
+
+			if status := c.Response().Status; err != nil || status < 200 || status >= 300 {
+				release()
+
+				return err
+			}
+
+			res := service.IdempotentResponse{
+				Status: c.Response().Status,
+				Header: make(map[string]string),
+				Body:   recorder.body.Bytes(),
+			}
+
+			for _, h := range replayedHeaders {
+				if v := c.Response().Header().Get(h); v != "" {
+					res.Header[h] = v
+				}
+			}
+
+			if err := store.Complete(ctx, k, res); err != nil {
+				c.Logger().Errorf("storing the response of the idempotency key failed: %v", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// replay answers with the response stored for an idempotency key.
+func replay(c echo.Context, rec service.IdempotencyRecord, fingerprint string) error {
+	if rec.Fingerprint != fingerprint {
+		return c.JSON(http.StatusUnprocessableEntity, buildErrorMap("The "+IdempotencyKeyHeader+" was already used with a different request"))
+	}
+
+	if rec.Response == nil {
+		return c.JSON(http.StatusConflict, buildErrorMap("A request with the same "+IdempotencyKeyHeader+" is in progress"))
+	}
+
+	for h, v := range rec.Response.Header {
+		c.Response().Header().Set(h, v)
+	}
+
+	c.Response().Header().Set(IdempotentReplayedHeader, "true")
+
+	return c.Blob(rec.Response.Status, rec.Response.Header[echo.HeaderContentType], rec.Response.Body)
+}
+
+// requestFingerprint returns a hash of what identifies the request: its method, path, query, player and body.
+// The body is read and restored for the handler.
+func requestFingerprint(c echo.Context) (string, error) {
+	req := c.Request()
+	body, err := io.ReadAll(req.Body)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	h := sha256.New()
+
+	for _, part := range []string{req.Method, req.URL.Path, req.URL.RawQuery, req.Header.Get(PlayerHeader)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// responseRecorder keeps a copy of the body written to the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,167 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/handler"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// newIdempotentServer returns a server answering the draw endpoint with the given handler behind the idempotency
+// middleware.
+func newIdempotentServer(h echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{DisablePrintStack: true}))
+	e.POST("/v1/decks/:uuid/draw", h,
+		handler.IdempotencyMiddleware(repository.NewInMemoryIdempotencyStore(), time.Hour))
+
+	return e
+}
+
+func serveDraw(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/decks/deck-1/draw", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(handler.IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type response struct {
+		status   int
+		body     string
+		replayed string
+	}
+
+	created := func(calls *atomic.Int32) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			calls.Add(1)
+			return c.JSON(http.StatusCreated, map[string]string{"deck_id": "deck-1"})
+		}
+	}
+
+	tests := []struct {
+		name      string
+		handler   func(calls *atomic.Int32) echo.HandlerFunc
+		requests  []struct{ key, body string }
+		want      []response
+		wantCalls int32
+	}{
+		{
+			name:    "replays the response of a retried request",
+			handler: created,
+			requests: []struct{ key, body string }{
+				{"key-1", `{"count":2}`},
+				{"key-1", `{"count":2}`},
+			},
+			want: []response{
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, ""},
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, "true"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "rejects the same key with a different body",
+			handler: created,
+			requests: []struct{ key, body string }{
+				{"key-1", `{"count":2}`},
+				{"key-1", `{"count":3}`},
+			},
+			want: []response{
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, ""},
+				{http.StatusUnprocessableEntity,
+					`{"message":"The Idempotency-Key was already used with a different request"}`, ""},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "repeats the requests with different keys",
+			handler: created,
+			requests: []struct{ key, body string }{
+				{"key-1", `{"count":2}`},
+				{"key-2", `{"count":2}`},
+			},
+			want: []response{
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, ""},
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, ""},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "releases the key when the handler panics",
+			handler: func(calls *atomic.Int32) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					if calls.Add(1) == 1 {
+						panic("failed unexpectedly")
+					}
+					return c.JSON(http.StatusCreated, map[string]string{"deck_id": "deck-1"})
+				}
+			},
+			requests: []struct{ key, body string }{
+				{"key-1", `{"count":2}`},
+				{"key-1", `{"count":2}`},
+			},
+			want: []response{
+				{http.StatusInternalServerError, `{"message":"Internal Server Error"}`, ""},
+				{http.StatusCreated, `{"deck_id":"deck-1"}`, ""},
+			},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			e := newIdempotentServer(tt.handler(&calls))
+
+			for i, r := range tt.requests {
+				rec := serveDraw(e, r.key, r.body)
+				got := response{rec.Code, strings.TrimSpace(rec.Body.String()),
+					rec.Header().Get(handler.IdempotentReplayedHeader)}
+				if got != tt.want[i] {
+					t.Errorf("request %d = %+v, want %+v", i+1, got, tt.want[i])
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_InFlight(t *testing.T) {
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	e := newIdempotentServer(func(c echo.Context) error {
+		close(entered)
+		<-unblock
+		return c.JSON(http.StatusCreated, map[string]string{"deck_id": "deck-1"})
+	})
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- serveDraw(e, "key-1", `{"count":2}`) }()
+	<-entered
+
+	rec := serveDraw(e, "key-1", `{"count":2}`)
+	want := `{"message":"A request with the same Idempotency-Key is in progress"}`
+	if got := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusConflict || got != want {
+		t.Errorf("retry while in flight = %d %s, want %d %s", rec.Code, got, http.StatusConflict, want)
+	}
+
+	close(unblock)
+	if rec := <-first; rec.Code != http.StatusCreated {
+		t.Errorf("first request status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	if rec := serveDraw(e, "key-1", `{"count":2}`); rec.Header().Get(handler.IdempotentReplayedHeader) != "true" {
+		t.Errorf("retry after completion wasn't replayed, status = %d", rec.Code)
+	}
+}
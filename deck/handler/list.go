@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	createdAfterQueryParam = "created_after"
+	minRemainingQueryParam = "min_remaining"
+	maxRemainingQueryParam = "max_remaining"
+	ownerQueryParam        = "owner"
+	cursorQueryParam       = "cursor"
+	limitQueryParam        = "limit"
+)
+
+// HandleListDecks handles the endpoint for listing the decks.
+func (h *DeckEchoHandler) HandleListDecks(c echo.Context) error {
+	filter, err := deckFilter(c)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	limit := 0
+
+	if limitStr := c.QueryParam(limitQueryParam); limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 1 || limit > service.MaxListLimit {
+			return mapError(c, badRequestError(fmt.Sprintf("Invalid limit, must be between 1 and %d", service.MaxListLimit)))
+		}
+	}
+
+	res, err := h.deckService.ListDecks(c.Request().Context(), filter, c.QueryParam(cursorQueryParam), limit)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// deckFilter parses the query parameters selecting the decks to list.
+func deckFilter(c echo.Context) (service.DeckFilter, error) {
+	var filter service.DeckFilter
+
+	if createdAfterStr := c.QueryParam(createdAfterQueryParam); createdAfterStr != "" {
+		createdAfter, err := time.Parse(time.RFC3339Nano, createdAfterStr)
+
+		if err != nil {
+			return filter, badRequestError("Invalid created_after, must be a time such as 2024-01-02T03:04:05Z")
+		}
+
+		filter.CreatedAfter = createdAfter
+	}
+
+	switch c.QueryParam(shuffledQueryParam) {
+	case "":
+	case "y":
+		shuffled := true
+		filter.Shuffled = &shuffled
+	case "n":
+		shuffled := false
+		filter.Shuffled = &shuffled
+	default:
+		return filter, badRequestError("Invalid shuffled, must be y or n")
+	}
+
+	var err error
+
+	if filter.MinRemaining, err = remainingBound(c, minRemainingQueryParam); err != nil {
+		return filter, err
+	}
+
+	if filter.MaxRemaining, err = remainingBound(c, maxRemainingQueryParam); err != nil {
+		return filter, err
+	}
+
+	filter.Owner = c.QueryParam(ownerQueryParam)
+
+	if tagsStr := c.QueryParam(tagsQueryParam); tagsStr != "" {
+		filter.Tags = strings.Split(tagsStr, ",")
+	}
+
+	return filter, nil
+}
+
+// remainingBound parses the query parameter bounding the remaining cards of the decks to list, nil if not given.
+func remainingBound(c echo.Context, param string) (*int, error) {
+	str := c.QueryParam(param)
+
+	if str == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(str)
+
+	if err != nil || n < 0 {
+		return nil, badRequestError(fmt.Sprintf("Invalid %s, must be greater or equal to 0", param))
+	}
+
+	return &n, nil
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/labstack/echo/v4"
+)
+
+type cutDeckRequest struct {
+	Index *int `json:"index"`
+}
+
+// HandleCutDeck handles the endpoint for cutting a deck.
+func (h *DeckEchoHandler) HandleCutDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := cutDeckRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	res, err := h.deckService.CutDeck(c.Request().Context(), uuid, req.Index)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type shuffleDeckRequest struct {
+	Times *int `json:"times"`
+}
+
+// times returns the times the shuffle is repeated, once if not given.
+func (r shuffleDeckRequest) times() (int, error) {
+	if r.Times == nil {
+		return 1, nil
+	}
+
+	if *r.Times < 1 || *r.Times > domain.MaxShuffleTimes {
+		return 0, badRequestError(fmt.Sprintf("Invalid times, must be between 1 and %d", domain.MaxShuffleTimes))
+	}
+
+	return *r.Times, nil
+}
+
+// HandleRiffleShuffle handles the endpoint for riffle shuffling a deck.
+func (h *DeckEchoHandler) HandleRiffleShuffle(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := shuffleDeckRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	times, err := req.times()
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.RiffleShuffle(c.Request().Context(), uuid, times)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleOverhandShuffle handles the endpoint for overhand shuffling a deck.
+func (h *DeckEchoHandler) HandleOverhandShuffle(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := shuffleDeckRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	times, err := req.times()
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.OverhandShuffle(c.Request().Context(), uuid, times)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
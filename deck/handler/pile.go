@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	pileParam = "pile"
+)
+
+type addToPileRequest struct {
+	Cards []string `json:"cards"`
+}
+
+// HandleAddToPile handles the endpoint for adding drawn cards to a pile of a deck.
+func (h *DeckEchoHandler) HandleAddToPile(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	pile := c.Param(pileParam)
+
+	req := addToPileRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	cards, err := domain.FromCodes(req.Cards)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.AddToPile(c.Request().Context(), uuid, pile, cards)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleListPile handles the endpoint for listing the cards of a pile of a deck.
+func (h *DeckEchoHandler) HandleListPile(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	pile := c.Param(pileParam)
+
+	res, err := h.deckService.ListPile(c.Request().Context(), uuid, pile)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleDrawFromPile handles the endpoint for drawing cards from a pile of a deck.
+func (h *DeckEchoHandler) HandleDrawFromPile(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	pile := c.Param(pileParam)
+
+	req := drawCardsRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	if req.Amount < 0 {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid amount, must be greater or equal to 0"))
+	}
+
+	res, err := h.deckService.DrawFromPile(c.Request().Context(), uuid, pile, req.Amount)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleShufflePile handles the endpoint for shuffling a pile of a deck.
+func (h *DeckEchoHandler) HandleShufflePile(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	pile := c.Param(pileParam)
+
+	res, err := h.deckService.ShufflePile(c.Request().Context(), uuid, pile)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
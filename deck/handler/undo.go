@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type undoDeckRequest struct {
+	Steps *int `json:"steps"`
+}
+
+// steps returns the number of operations to undo, one if not given.
+func (r undoDeckRequest) steps() (int, error) {
+	if r.Steps == nil {
+		return 1, nil
+	}
+
+	if *r.Steps < 1 {
+		return 0, badRequestError("Invalid steps, must be greater than 0")
+	}
+
+	return *r.Steps, nil
+}
+
+// HandleUndoDeck handles the endpoint for undoing the last operations on a deck.
+func (h *DeckEchoHandler) HandleUndoDeck(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	req := undoDeckRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	steps, err := req.steps()
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	res, err := h.deckService.UndoDeck(c.Request().Context(), uuid, steps)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ETagHeader is the header holding the version of the deck returned by the endpoints.
+	ETagHeader = "ETag"
+	// IfMatchHeader is the header holding the version a request expects the deck to be at.
+	IfMatchHeader = "If-Match"
+)
+
+// IfMatchMiddleware sets the version given in the IfMatchHeader header in the context of the request, so the services
+// fail to modify a deck at another version. The header must hold a single ETag returned by the endpoints, or * to
+// match any version. Answers with a 400 status if the header is invalid.
+func IfMatchMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ifMatch := strings.TrimSpace(c.Request().Header.Get(IfMatchHeader))
+
+		if ifMatch == "" || ifMatch == "*" {
+			return next(c)
+		}
+
+		version, ok := parseETag(ifMatch)
+
+		if !ok {
+			return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid If-Match header, must be an ETag returned by the API"))
+		}
+
+		req := c.Request()
+		c.SetRequest(req.WithContext(service.ContextWithExpectedVersion(req.Context(), version)))
+
+		return next(c)
+	}
+}
+
+// setETag sets the ETag header of the response to the given version of a deck.
+func setETag(c echo.Context, version int64) {
+	c.Response().Header().Set(ETagHeader, strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// parseETag returns the version of a deck in the given ETag.
+func parseETag(etag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(etag)
+
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+
+	return version, err == nil && version >= 0
+}
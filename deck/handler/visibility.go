@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	playerParam = "player"
+	// PlayerHeader is the header identifying the player making a request, whose view of the decks is returned.
+	PlayerHeader = "X-Player"
+)
+
+// PlayerMiddleware sets the player given in the PlayerHeader header in the context of the request, so the services
+// hide the cards the player can't see. Requests without the header are viewed as by anyone not playing.
+// The header is trusted as is, so it must be set by a trusted upstream authenticating the players.
+func PlayerMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		player := c.Request().Header.Get(PlayerHeader)
+
+		if player == "" {
+			return next(c)
+		}
+
+		if err := domain.ValidatePlayerName(player); err != nil {
+			return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid "+PlayerHeader+" header, must be a valid player name"))
+		}
+
+		req := c.Request()
+		c.SetRequest(req.WithContext(service.ContextWithPlayer(req.Context(), player)))
+
+		return next(c)
+	}
+}
+
+// HandleListHand handles the endpoint for listing the cards of the hand of a player in a deck.
+func (h *DeckEchoHandler) HandleListHand(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	player := c.Param(playerParam)
+
+	res, err := h.deckService.ListHand(c.Request().Context(), uuid, player)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleSetDeckVisibility handles the endpoint for setting the visibility of the remaining cards of a deck.
+func (h *DeckEchoHandler) HandleSetDeckVisibility(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+
+	v := domain.Visibility{}
+
+	if err := c.Bind(&v); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	res, err := h.deckService.SetDeckVisibility(c.Request().Context(), uuid, v)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleSetPileVisibility handles the endpoint for setting the visibility of a pile of a deck.
+func (h *DeckEchoHandler) HandleSetPileVisibility(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	pile := c.Param(pileParam)
+
+	v := domain.Visibility{}
+
+	if err := c.Bind(&v); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	res, err := h.deckService.SetPileVisibility(c.Request().Context(), uuid, pile, v)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// HandleSetHandVisibility handles the endpoint for setting the visibility of the hand of a player in a deck.
+func (h *DeckEchoHandler) HandleSetHandVisibility(c echo.Context) error {
+	uuid := c.Param(uuidParam)
+	player := c.Param(playerParam)
+
+	v := domain.Visibility{}
+
+	if err := c.Bind(&v); err != nil {
+		return c.JSON(http.StatusBadRequest, buildErrorMap("Invalid body"))
+	}
+
+	res, err := h.deckService.SetHandVisibility(c.Request().Context(), uuid, player, v)
+
+	if err != nil {
+		return mapError(c, err)
+	}
+
+	setETag(c, res.Version)
+
+	return c.JSON(http.StatusOK, res)
+}
@@ -3,37 +3,238 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
 )
 
+// tombstoneRetention is for how long the repositories remember the decks they swept, answering that they expired
+// instead of that they aren't found.
+const tombstoneRetention = 7 * 24 * time.Hour
+
 // InMemoryDeckRepository represents a repository of decks implemented using memory.
+// It is safe for concurrent use. Decks are copied when saved and retrieved, so the callers
+// never share a deck with the repository or with each other.
 type InMemoryDeckRepository struct {
+	mu    sync.RWMutex
 	decks map[string]*domain.Deck
+	// histories are the events of the decks, by UUID. The events are immutable, so they are shared with the callers.
+	histories map[string]domain.History
+	// tombstones are the times the swept decks were deleted at, by UUID.
+	tombstones map[string]time.Time
 }
 
 // NewInMemoryDeckRepository returns a new InMemoryDeckRepository.
 func NewInMemoryDeckRepository() *InMemoryDeckRepository {
 	return &InMemoryDeckRepository{
-		decks: make(map[string]*domain.Deck),
+		decks:      make(map[string]*domain.Deck),
+		histories:  make(map[string]domain.History),
+		tombstones: make(map[string]time.Time),
 	}
 }
 
-// Save saves the given deck in memory.
-// Returns an error thinking about possible future implementations using some database.
+// Save saves the given deck in memory, appends its pending events to its history and increments its version.
+// The pending events of the deck are cleared once saved.
+// Returns an error if the version of the deck isn't the stored one, because the deck was saved since it was retrieved.
 func (r *InMemoryDeckRepository) Save(_ context.Context, d *domain.Deck) error {
-	r.decks[d.UUID] = d
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkVersion(d); err != nil {
+		return err
+	}
+
+	d.Version++
+	r.store(d.Clone())
+	d.Pending = nil
+
+	return nil
+}
+
+// checkVersion returns an error if the version of the deck isn't the stored one, 0 if the deck isn't stored.
+// The lock must be held.
+func (r *InMemoryDeckRepository) checkVersion(d *domain.Deck) error {
+	var stored int64
+
+	if s, ok := r.decks[d.UUID]; ok {
+		stored = s.Version
+	}
+
+	if d.Version != stored {
+		return fmt.Errorf("%w: the deck is at version %d, not %d", domain.ErrVersionConflict, stored, d.Version)
+	}
 
 	return nil
 }
 
+// put stores the deck as is, without checking nor incrementing its version, appending its pending events to its
+// history.
+func (r *InMemoryDeckRepository) put(d *domain.Deck) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store(d)
+}
+
+// store stores the deck without its pending events, which are appended to its history. The lock must be held.
+func (r *InMemoryDeckRepository) store(d *domain.Deck) {
+	r.histories[d.UUID] = appendEvents(r.histories[d.UUID], d.Pending)
+	d.Pending = nil
+	r.decks[d.UUID] = d
+}
+
+// appendEvents returns the history followed by the given events. The events replace the ones of the history from
+// the number of the first of them on, so a whole history given again isn't repeated.
+func appendEvents(h domain.History, events []domain.Event) domain.History {
+	if len(events) == 0 {
+		return h
+	}
+
+	n := max(0, min(events[0].Number-1, len(h)))
+
+	return append(h[:n:n], events...)
+}
+
 // Get gets the deck with the given UUID. Returns an error if the deck is not found.
 func (r *InMemoryDeckRepository) Get(_ context.Context, uuid string) (*domain.Deck, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	d, ok := r.decks[uuid]
 
 	if !ok {
+		if _, swept := r.tombstones[uuid]; swept {
+			return nil, domain.ErrDeckExpired
+		}
+
+		return nil, domain.ErrDeckNotFound
+	}
+
+	return d.Clone(), nil
+}
+
+// History returns the events of the deck with the given UUID, in the order they happened. Returns an error if the
+// deck is not found.
+func (r *InMemoryDeckRepository) History(_ context.Context, uuid string) (domain.History, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.decks[uuid]; !ok {
+		if _, swept := r.tombstones[uuid]; swept {
+			return nil, domain.ErrDeckExpired
+		}
+
 		return nil, domain.ErrDeckNotFound
 	}
 
-	return d, nil
+	return append(domain.History(nil), r.histories[uuid]...), nil
+}
+
+// Delete deletes the deck with the given UUID. Returns an error if the deck is not found.
+func (r *InMemoryDeckRepository) Delete(_ context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.decks[uuid]; !ok {
+		if _, swept := r.tombstones[uuid]; swept {
+			return domain.ErrDeckExpired
+		}
+
+		return domain.ErrDeckNotFound
+	}
+
+	delete(r.decks, uuid)
+	delete(r.histories, uuid)
+
+	return nil
+}
+
+// List returns up to q.Limit summaries of the decks selected by the query, ordered by creation time and then UUID.
+func (r *InMemoryDeckRepository) List(_ context.Context, q service.DeckQuery) ([]service.DeckSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decks := make([]*domain.Deck, 0, len(r.decks))
+
+	for _, d := range r.decks {
+		decks = append(decks, d)
+	}
+
+	return listDecks(q, decks), nil
+}
+
+// listDecks returns up to q.Limit summaries of the given decks selected by the query, ordered by creation time and
+// then UUID.
+func listDecks(q service.DeckQuery, all []*domain.Deck) []service.DeckSummary {
+	var decks []service.DeckSummary
+
+	for _, d := range all {
+		if !q.Filter.Matches(d) {
+			continue
+		}
+
+		s := service.SummarizeDeck(d)
+		// The tags are shared with the stored deck.
+		s.Tags = append([]string(nil), s.Tags...)
+
+		if q.After == nil || q.After.Precedes(s) {
+			decks = append(decks, s)
+		}
+	}
+
+	sort.Slice(decks, func(i, j int) bool {
+		return service.DeckCursor{CreatedAt: decks[i].CreatedAt, UUID: decks[i].DeckID}.Precedes(decks[j])
+	})
+
+	if len(decks) > q.Limit {
+		decks = decks[:q.Limit]
+	}
+
+	return decks
+}
+
+// Sweep deletes the decks sweepable at the given time and returns how many were deleted. The deleted decks are
+// remembered for a week, so getting them returns domain.ErrDeckExpired.
+func (r *InMemoryDeckRepository) Sweep(_ context.Context, now, idleBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	swept := 0
+
+	for uuid, d := range r.decks {
+		if d.Sweepable(now, idleBefore) {
+			delete(r.decks, uuid)
+			delete(r.histories, uuid)
+			r.tombstones[uuid] = now
+			swept++
+		}
+	}
+
+	for uuid, deletedAt := range r.tombstones {
+		if now.Sub(deletedAt) > tombstoneRetention {
+			delete(r.tombstones, uuid)
+		}
+	}
+
+	return swept, nil
+}
+
+// sweepable returns how many decks are sweepable at the given time.
+func (r *InMemoryDeckRepository) sweepable(now, idleBefore time.Time) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+
+	for _, d := range r.decks {
+		if d.Sweepable(now, idleBefore) {
+			n++
+		}
+	}
+
+	return n
 }
@@ -0,0 +1,279 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// testVersioning checks that the repository increments the version of the decks saved and rejects stale saves.
+func testVersioning(t *testing.T, r service.DeckRepository) {
+	t.Helper()
+	ctx := context.Background()
+	d := testDeck("versioned")
+	if err := r.Save(ctx, d); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if d.Version != 1 {
+		t.Errorf("Save() version = %d, want 1", d.Version)
+	}
+
+	stale, err := r.Get(ctx, d.UUID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	d.Draw(1)
+	if err := r.Save(ctx, d); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	stale.Draw(1)
+	if err := r.Save(ctx, stale); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("Save() stale deck error = %v, want %v", err, domain.ErrVersionConflict)
+	}
+
+	got, err := r.Get(ctx, d.UUID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Version != 2 || len(got.Cards) != len(d.Cards) {
+		t.Errorf("Get() = %v, want the deck saved at version 2", got)
+	}
+}
+
+func TestInMemoryDeckRepository(t *testing.T) {
+	t.Run("rejects stale saves", func(t *testing.T) {
+		testVersioning(t, repository.NewInMemoryDeckRepository())
+	})
+	t.Run("sweeps decks", func(t *testing.T) {
+		testSweeping(t, repository.NewInMemoryDeckRepository())
+	})
+	t.Run("deletes decks", func(t *testing.T) {
+		testDeleting(t, repository.NewInMemoryDeckRepository())
+	})
+	t.Run("lists decks", func(t *testing.T) {
+		testListing(t, repository.NewInMemoryDeckRepository())
+	})
+	t.Run("stores the history apart from the decks", func(t *testing.T) {
+		testHistory(t, repository.NewInMemoryDeckRepository())
+	})
+}
+
+// testHistory checks that the repository stores the pending events of the decks saved apart from them, in order,
+// and deletes them along with the decks.
+func testHistory(t *testing.T, r service.DeckRepository) {
+	t.Helper()
+	ctx := context.Background()
+	at := time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC)
+	for _, d := range []*domain.Deck{testDeck("deleted"), testDeck("kept")} {
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if d.Pending != nil {
+			t.Errorf("Save() pending events = %v, want none", d.Pending)
+		}
+		d.Draw(1)
+		d.Record(domain.DrawnEvent, at, "request-2", "bob")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	got, err := r.Get(ctx, "kept")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Pending != nil || got.LastEvent != 2 {
+		t.Errorf("Get() = %v, want the deck without events, after the event 2", got)
+	}
+
+	history, err := r.History(ctx, "kept")
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Type != domain.CreatedEvent || history[1].Type != domain.DrawnEvent ||
+		history[1].Player != "bob" || !history[1].At.Equal(at) {
+		t.Fatalf("History() = %v, want the created and the drawn events", history)
+	}
+	state, err := history.StateAt(2)
+	if err != nil {
+		t.Fatalf("History.StateAt() error = %v", err)
+	}
+	if !reflect.DeepEqual(state.Cards, got.Cards) || !reflect.DeepEqual(state.Drawn, got.Drawn) ||
+		state.Version != got.Version {
+		t.Errorf("History.StateAt() = %v, want %v", state, got)
+	}
+
+	if err := r.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for _, uuid := range []string{"deleted", "missing"} {
+		if _, err := r.History(ctx, uuid); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("History(%q) error = %v, want %v", uuid, err, domain.ErrDeckNotFound)
+		}
+	}
+}
+
+// testSweeping checks that the repository deletes the sweepable decks and remembers them as expired.
+func testSweeping(t *testing.T, r service.DeckRepository) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := now.Add(-time.Second)
+
+	expired := testDeck("expired")
+	expired.ExpiresAt = &expiresAt
+	idle := testDeck("idle")
+	idle.Cards = nil
+	idle.UpdatedAt = now.Add(-2 * time.Hour)
+	closed := testDeck("closed")
+	closed.ClosedAt = &idle.UpdatedAt
+	closed.UpdatedAt = idle.UpdatedAt
+	kept := testDeck("kept")
+	for _, d := range []*domain.Deck{expired, idle, closed, kept} {
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	swept, err := r.Sweep(ctx, now, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if swept != 3 {
+		t.Errorf("Sweep() = %d, want 3", swept)
+	}
+	for _, uuid := range []string{"expired", "idle", "closed"} {
+		if _, err := r.Get(ctx, uuid); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("Get(%q) error = %v, want %v", uuid, err, domain.ErrDeckExpired)
+		}
+	}
+	if _, err := r.Get(ctx, "kept"); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+	if _, err := r.Get(ctx, "missing"); !errors.Is(err, domain.ErrDeckNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+	}
+}
+
+// testDeleting checks that the repository deletes decks, keeping the rest, and that it stores closed decks.
+func testDeleting(t *testing.T, r service.DeckRepository) {
+	t.Helper()
+	ctx := context.Background()
+	closedAt := time.Date(2024, 1, 2, 3, 4, 7, 0, time.UTC)
+	deleted := testDeck("deleted")
+	deleted.ClosedAt = &closedAt
+	for _, d := range []*domain.Deck{deleted, testDeck("kept")} {
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	got, err := r.Get(ctx, "deleted")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, deleted) {
+		t.Errorf("Get() = %v, want %v", got, deleted)
+	}
+	if err := r.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := r.Get(ctx, "deleted"); !errors.Is(err, domain.ErrDeckNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+	}
+	if err := r.Delete(ctx, "deleted"); !errors.Is(err, domain.ErrDeckNotFound) {
+		t.Errorf("Delete() error = %v, want %v", err, domain.ErrDeckNotFound)
+	}
+	if _, err := r.Get(ctx, "kept"); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+}
+
+// testListing checks that the repository lists the decks selected by the queries, in order and from the cursors.
+func testListing(t *testing.T, r service.DeckRepository) {
+	t.Helper()
+	ctx := context.Background()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	decks := []struct {
+		uuid      string
+		createdAt time.Time
+		shuffled  bool
+		remaining int
+		owner     string
+		tags      []string
+	}{
+		{uuid: "d", createdAt: createdAt.Add(2 * time.Second), shuffled: true, remaining: 1, owner: "bob"},
+		{uuid: "c", createdAt: createdAt.Add(time.Second), shuffled: true, remaining: 2, owner: "alice", tags: []string{"blackjack"}},
+		{uuid: "b", createdAt: createdAt.Add(time.Second), remaining: 0, owner: "bob", tags: []string{"poker"}},
+		{uuid: "a", createdAt: createdAt, shuffled: true, remaining: 2, owner: "alice", tags: []string{"blackjack", "table-1"}},
+	}
+	for _, deck := range decks {
+		d := testDeck(deck.uuid)
+		d.CreatedAt, d.Shuffled, d.Cards, d.Owner, d.Tags = deck.createdAt, deck.shuffled, d.Cards[:deck.remaining], deck.owner, deck.tags
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	yes, no, one, two := true, false, 1, 2
+	tests := []struct {
+		name string
+		q    service.DeckQuery
+		want []string
+	}{
+		{name: "every deck", q: service.DeckQuery{Limit: 10}, want: []string{"a", "b", "c", "d"}},
+		{name: "first page", q: service.DeckQuery{Limit: 2}, want: []string{"a", "b"}},
+		{
+			name: "next page",
+			q:    service.DeckQuery{After: &service.DeckCursor{CreatedAt: createdAt.Add(time.Second), UUID: "b"}, Limit: 2},
+			want: []string{"c", "d"},
+		},
+		{name: "created after", q: service.DeckQuery{Filter: service.DeckFilter{CreatedAfter: createdAt}, Limit: 10}, want: []string{"b", "c", "d"}},
+		{name: "shuffled", q: service.DeckQuery{Filter: service.DeckFilter{Shuffled: &yes}, Limit: 10}, want: []string{"a", "c", "d"}},
+		{name: "unshuffled", q: service.DeckQuery{Filter: service.DeckFilter{Shuffled: &no}, Limit: 10}, want: []string{"b"}},
+		{
+			name: "remaining range",
+			q:    service.DeckQuery{Filter: service.DeckFilter{MinRemaining: &one, MaxRemaining: &one}, Limit: 10},
+			want: []string{"d"},
+		},
+		{name: "minimum remaining", q: service.DeckQuery{Filter: service.DeckFilter{MinRemaining: &two}, Limit: 10}, want: []string{"a", "c"}},
+		{name: "owner", q: service.DeckQuery{Filter: service.DeckFilter{Owner: "bob"}, Limit: 10}, want: []string{"b", "d"}},
+		{
+			name: "every tag",
+			q:    service.DeckQuery{Filter: service.DeckFilter{Tags: []string{"table-1", "blackjack"}}, Limit: 10},
+			want: []string{"a"},
+		},
+		{name: "no deck", q: service.DeckQuery{Filter: service.DeckFilter{Owner: "carol"}, Limit: 10}},
+	}
+	for _, tt := range tests {
+		got, err := r.List(ctx, tt.q)
+		if err != nil {
+			t.Fatalf("List() %s error = %v", tt.name, err)
+		}
+		var uuids []string
+		for _, s := range got {
+			uuids = append(uuids, s.DeckID)
+		}
+		if !reflect.DeepEqual(uuids, tt.want) {
+			t.Errorf("List() %s = %v, want %v", tt.name, uuids, tt.want)
+		}
+	}
+
+	got, err := r.List(ctx, service.DeckQuery{Limit: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	saved, err := r.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want := []service.DeckSummary{service.SummarizeDeck(saved)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %+v, want %+v", got, want)
+	}
+}
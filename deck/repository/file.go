@@ -0,0 +1,459 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+const (
+	walFileName      = "decks.wal"
+	snapshotFileName = "decks.snapshot"
+
+	defaultSnapshotEvery = 1000
+)
+
+const (
+	walOpSave   = "save"
+	walOpDelete = "delete"
+	walOpSweep  = "sweep"
+)
+
+// walRecord represents a deck mutation appended to the write-ahead log. Deletes are recorded with the UUID of the
+// deck. Sweeps are recorded with their parameters, replaying them deletes the same decks.
+type walRecord struct {
+	Op         string    `json:"op"`
+	Deck       *fileDeck `json:"deck,omitempty"`
+	UUID       string    `json:"uuid,omitempty"`
+	Now        time.Time `json:"now"`
+	IdleBefore time.Time `json:"idle_before"`
+}
+
+// fileDeck represents a deck written to disk along with events of its history: its whole history in the snapshot,
+// and only the events recorded since it was last saved in the write-ahead log.
+type fileDeck struct {
+	*domain.Deck
+	History domain.History `json:"history,omitempty"`
+}
+
+// pending returns the deck with the written events as its pending events, so storing it appends them to its history.
+func (f *fileDeck) pending() *domain.Deck {
+	d := f.Deck
+	d.Pending = f.History
+
+	// Decks written before their history was stored apart don't have their last event.
+	if n := len(f.History); n > 0 && d.LastEvent < f.History[n-1].Number {
+		d.LastEvent = f.History[n-1].Number
+	}
+
+	return d
+}
+
+// fileSnapshot represents every deck written to the snapshot, along with the times the swept decks were deleted at,
+// by UUID. Snapshots written before the swept decks were kept in them only hold the list of decks.
+type fileSnapshot struct {
+	Decks      []*fileDeck          `json:"decks"`
+	Tombstones map[string]time.Time `json:"tombstones,omitempty"`
+}
+
+type fileOptions struct {
+	snapshotEvery int
+}
+
+// FileOption is the interface implemented to allow options while opening a FileDeckRepository.
+type FileOption interface {
+	apply(*fileOptions)
+}
+
+type snapshotEveryOption int
+
+func (o snapshotEveryOption) apply(opts *fileOptions) {
+	opts.snapshotEvery = int(o)
+}
+
+// SnapshotEvery option to determine after how many mutations the write-ahead log is compacted into a snapshot.
+func SnapshotEvery(n int) FileOption {
+	return snapshotEveryOption(n)
+}
+
+// FileDeckRepository represents a repository of decks persisted in a directory.
+// Every mutation is appended to a write-ahead log before being applied in memory, and the log is periodically
+// compacted into a snapshot. When opened, the snapshot and the log are replayed to recover the decks.
+// It is safe for concurrent use.
+type FileDeckRepository struct {
+	mu            sync.Mutex
+	memory        *InMemoryDeckRepository
+	dir           string
+	wal           *os.File
+	walRecords    int
+	snapshotEvery int
+}
+
+// NewFileDeckRepository opens the repository stored in the given directory, creating it if it doesn't exist.
+// Returns an error if the directory can't be created or the stored decks can't be recovered.
+func NewFileDeckRepository(dir string, opts ...FileOption) (*FileDeckRepository, error) {
+	options := fileOptions{
+		snapshotEvery: defaultSnapshotEvery,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating the storage directory failed: %w", err)
+	}
+
+	r := &FileDeckRepository{
+		memory:        NewInMemoryDeckRepository(),
+		dir:           dir,
+		snapshotEvery: options.snapshotEvery,
+	}
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, fmt.Errorf("loading the snapshot failed: %w", err)
+	}
+
+	if err := r.replayWAL(); err != nil {
+		return nil, fmt.Errorf("replaying the write-ahead log failed: %w", err)
+	}
+
+	return r, nil
+}
+
+// Save appends the given deck to the write-ahead log along with its pending events, then saves it in memory and
+// increments its version. The pending events of the deck are cleared once saved. Returns an error if the version of
+// the deck isn't the stored one or if the deck couldn't be written to disk, in which case the deck is not saved.
+func (r *FileDeckRepository) Save(_ context.Context, d *domain.Deck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.memory.mu.RLock()
+	err := r.memory.checkVersion(d)
+	r.memory.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	saved := d.Clone()
+	saved.Version++
+
+	if err := r.append(walRecord{Op: walOpSave, Deck: &fileDeck{Deck: saved, History: saved.Pending}}); err != nil {
+		return err
+	}
+
+	r.memory.put(saved)
+	d.Version = saved.Version
+	d.Pending = nil
+	r.compactIfDue()
+
+	return nil
+}
+
+// Delete appends the deletion to the write-ahead log and then deletes the deck with the given UUID from memory.
+// Returns an error if the deck is not found or if the deletion couldn't be written to disk, in which case the deck
+// is not deleted.
+func (r *FileDeckRepository) Delete(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.memory.Get(ctx, uuid); err != nil {
+		return err
+	}
+
+	if err := r.append(walRecord{Op: walOpDelete, UUID: uuid}); err != nil {
+		return err
+	}
+
+	if err := r.memory.Delete(ctx, uuid); err != nil {
+		return err
+	}
+
+	r.compactIfDue()
+
+	return nil
+}
+
+// Sweep appends the sweep to the write-ahead log and then deletes the decks sweepable at the given time from memory.
+// Returns how many decks were deleted, or an error if the sweep couldn't be written to disk, in which case no deck
+// is deleted. The deleted decks are remembered for a week, also in the snapshot once the log is compacted.
+func (r *FileDeckRepository) Sweep(ctx context.Context, now, idleBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.memory.sweepable(now, idleBefore) == 0 {
+		return 0, nil
+	}
+
+	if err := r.append(walRecord{Op: walOpSweep, Now: now, IdleBefore: idleBefore}); err != nil {
+		return 0, err
+	}
+
+	swept, err := r.memory.Sweep(ctx, now, idleBefore)
+
+	if err != nil {
+		return 0, err
+	}
+
+	r.compactIfDue()
+
+	return swept, nil
+}
+
+// Get gets the deck with the given UUID. Returns an error if the deck is not found.
+func (r *FileDeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
+	return r.memory.Get(ctx, uuid)
+}
+
+// History returns the events of the deck with the given UUID, in the order they happened. Returns an error if the
+// deck is not found.
+func (r *FileDeckRepository) History(ctx context.Context, uuid string) (domain.History, error) {
+	return r.memory.History(ctx, uuid)
+}
+
+// List returns up to q.Limit summaries of the decks selected by the query, ordered by creation time and then UUID.
+func (r *FileDeckRepository) List(ctx context.Context, q service.DeckQuery) ([]service.DeckSummary, error) {
+	return r.memory.List(ctx, q)
+}
+
+// Close compacts the write-ahead log into a snapshot and releases the files used by the repository.
+func (r *FileDeckRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.snapshot(); err != nil {
+		return err
+	}
+
+	return r.wal.Close()
+}
+
+// compactIfDue compacts the write-ahead log into a snapshot once it has enough records. The mutations in the log are
+// already durable, so a failed compaction is only logged and retried after the next mutation.
+func (r *FileDeckRepository) compactIfDue() {
+	if r.snapshotEvery <= 0 || r.walRecords < r.snapshotEvery {
+		return
+	}
+
+	if err := r.snapshot(); err != nil {
+		log.Printf("Error compacting the write-ahead log: %v", err)
+	}
+}
+
+func (r *FileDeckRepository) apply(rec walRecord) error {
+	switch rec.Op {
+	case walOpSave:
+		if rec.Deck == nil || rec.Deck.Deck == nil {
+			return errors.New("save record without deck")
+		}
+
+		r.memory.put(rec.Deck.pending())
+
+		return nil
+	case walOpDelete:
+		err := r.memory.Delete(context.Background(), rec.UUID)
+
+		// The deletion may already be in the snapshot, if the log couldn't be emptied after writing it.
+		if errors.Is(err, domain.ErrDeckNotFound) || errors.Is(err, domain.ErrDeckExpired) {
+			return nil
+		}
+
+		return err
+	case walOpSweep:
+		_, err := r.memory.Sweep(context.Background(), rec.Now, rec.IdleBefore)
+
+		return err
+	default:
+		return fmt.Errorf("unknown operation %q", rec.Op)
+	}
+}
+
+func (r *FileDeckRepository) append(rec walRecord) error {
+	line, err := json.Marshal(rec)
+
+	if err != nil {
+		return fmt.Errorf("encoding the log record failed: %w", err)
+	}
+
+	if _, err := r.wal.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing the log record failed: %w", err)
+	}
+
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("syncing the log failed: %w", err)
+	}
+
+	r.walRecords++
+
+	return nil
+}
+
+func (r *FileDeckRepository) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, snapshotFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var snapshot fileSnapshot
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &snapshot.Decks)
+	} else {
+		err = json.Unmarshal(data, &snapshot)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, d := range snapshot.Decks {
+		r.memory.put(d.pending())
+	}
+
+	r.memory.mu.Lock()
+	defer r.memory.mu.Unlock()
+
+	for uuid, deletedAt := range snapshot.Tombstones {
+		r.memory.tombstones[uuid] = deletedAt
+	}
+
+	return nil
+}
+
+// replayWAL applies every complete record of the log and leaves it open for appending.
+// A trailing incomplete record, left by a crash in the middle of a write, is discarded.
+func (r *FileDeckRepository) replayWAL() error {
+	f, err := os.OpenFile(filepath.Join(r.dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(f)
+
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		var rec walRecord
+
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			f.Close()
+			return fmt.Errorf("decoding the record at offset %d failed: %w", offset, err)
+		}
+
+		if err := r.apply(rec); err != nil {
+			f.Close()
+			return fmt.Errorf("applying the record at offset %d failed: %w", offset, err)
+		}
+
+		offset += int64(len(line))
+		r.walRecords++
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	r.wal = f
+
+	return nil
+}
+
+// snapshot writes every deck and the swept decks remembered into a new snapshot and empties the write-ahead log.
+// The snapshot is written to a temporary file and renamed, so a crash never leaves a partial snapshot.
+func (r *FileDeckRepository) snapshot() error {
+	r.memory.mu.RLock()
+	snapshot := fileSnapshot{
+		Decks:      make([]*fileDeck, 0, len(r.memory.decks)),
+		Tombstones: r.memory.tombstones,
+	}
+
+	for uuid, d := range r.memory.decks {
+		snapshot.Decks = append(snapshot.Decks, &fileDeck{Deck: d, History: r.memory.histories[uuid]})
+	}
+
+	data, err := json.Marshal(snapshot)
+	r.memory.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("encoding the snapshot failed: %w", err)
+	}
+
+	path := filepath.Join(r.dir, snapshotFileName)
+	tmpPath := path + ".tmp"
+
+	if err := writeFileSync(tmpPath, data); err != nil {
+		return fmt.Errorf("writing the snapshot failed: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing the snapshot failed: %w", err)
+	}
+
+	if err := r.wal.Truncate(0); err != nil {
+		return fmt.Errorf("truncating the log failed: %w", err)
+	}
+
+	if _, err := r.wal.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("truncating the log failed: %w", err)
+	}
+
+	r.walRecords = 0
+
+	return nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
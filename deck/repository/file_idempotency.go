@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+const (
+	idempotencyFileName = "idempotency.log"
+
+	// idempotencyCompactEvery is after how many completed keys the log of a FileIdempotencyStore is compacted.
+	idempotencyCompactEvery = 1000
+)
+
+// idempotencyLogRecord represents a completed idempotency key appended to the log of a FileIdempotencyStore.
+type idempotencyLogRecord struct {
+	DeckID      string                      `json:"deck_id,omitempty"`
+	Key         string                      `json:"key"`
+	Fingerprint string                      `json:"fingerprint"`
+	Response    *service.IdempotentResponse `json:"response"`
+	ExpiresAt   time.Time                   `json:"expires_at"`
+}
+
+// FileIdempotencyStore represents a store of idempotency keys persisted in a directory, next to the decks of a
+// FileDeckRepository. The keys are held in memory and the completed ones are appended to a log, which is compacted
+// once it has enough records, so their responses are replayed after a restart. The keys in progress aren't written,
+// as their requests are interrupted by a restart and can be retried. It is safe for concurrent use.
+type FileIdempotencyStore struct {
+	mu         sync.Mutex
+	memory     *InMemoryIdempotencyStore
+	dir        string
+	log        *os.File
+	logRecords int
+}
+
+// NewFileIdempotencyStore opens the store kept in the given directory, creating it if it doesn't exist. The keys
+// expired at the given time aren't recovered. Returns an error if the directory can't be created or the stored keys
+// can't be recovered.
+func NewFileIdempotencyStore(dir string, now time.Time) (*FileIdempotencyStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating the storage directory failed: %w", err)
+	}
+
+	s := &FileIdempotencyStore{
+		memory: NewInMemoryIdempotencyStore(),
+		dir:    dir,
+	}
+
+	if err := s.load(now); err != nil {
+		return nil, fmt.Errorf("loading the idempotency keys failed: %w", err)
+	}
+
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reserve stores a record in progress for the key, unless the key has a record not expired at the given time,
+// which is returned instead.
+func (s *FileIdempotencyStore) Reserve(ctx context.Context, key service.IdempotencyKey, fingerprint string, now time.Time, ttl time.Duration) (service.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.memory.Reserve(ctx, key, fingerprint, now, ttl)
+}
+
+// Complete appends the response of the request that reserved the key to the log and then stores it in memory.
+// Does nothing if the key isn't reserved. Returns an error if the response couldn't be written to disk, in which
+// case it is not stored.
+func (s *FileIdempotencyStore) Complete(ctx context.Context, key service.IdempotencyKey, res service.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memory.mu.Lock()
+	rec, ok := s.memory.records[key]
+	s.memory.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	err := s.append(idempotencyLogRecord{
+		DeckID:      key.DeckID,
+		Key:         key.Key,
+		Fingerprint: rec.Fingerprint,
+		Response:    &res,
+		ExpiresAt:   rec.ExpiresAt,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.memory.Complete(ctx, key, res); err != nil {
+		return err
+	}
+
+	// The response is already durable, so a failed compaction is only logged and retried after the next response.
+	if s.logRecords >= idempotencyCompactEvery {
+		if err := s.compact(); err != nil {
+			log.Printf("Error compacting the idempotency keys: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// Release forgets the key. Only the keys in progress are released, and those aren't written to disk.
+func (s *FileIdempotencyStore) Release(ctx context.Context, key service.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.memory.Release(ctx, key)
+}
+
+// Close releases the log of the store.
+func (s *FileIdempotencyStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.log.Close()
+}
+
+// load reads the completed keys of the log not expired at the given time into memory.
+// A trailing incomplete record, left by a crash in the middle of a write, is discarded.
+func (s *FileIdempotencyStore) load(now time.Time) error {
+	f, err := os.Open(filepath.Join(s.dir, idempotencyFileName))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var rec idempotencyLogRecord
+
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			return fmt.Errorf("decoding the record at offset %d failed: %w", offset, err)
+		}
+
+		offset += int64(len(line))
+
+		if rec.ExpiresAt.After(now) {
+			s.memory.records[service.IdempotencyKey{DeckID: rec.DeckID, Key: rec.Key}] = service.IdempotencyRecord{
+				Fingerprint: rec.Fingerprint,
+				Response:    rec.Response,
+				ExpiresAt:   rec.ExpiresAt,
+			}
+		}
+	}
+}
+
+// compact writes the completed keys held in memory into a new log, leaving out the ones already forgotten, and
+// leaves it open for appending. The log is written to a temporary file and renamed, so a crash never leaves a
+// partial log.
+func (s *FileIdempotencyStore) compact() error {
+	var buf bytes.Buffer
+
+	s.memory.mu.Lock()
+
+	for key, rec := range s.memory.records {
+		if rec.Response == nil {
+			continue
+		}
+
+		line, err := json.Marshal(idempotencyLogRecord{
+			DeckID:      key.DeckID,
+			Key:         key.Key,
+			Fingerprint: rec.Fingerprint,
+			Response:    rec.Response,
+			ExpiresAt:   rec.ExpiresAt,
+		})
+
+		if err != nil {
+			s.memory.mu.Unlock()
+			return fmt.Errorf("encoding the idempotency keys failed: %w", err)
+		}
+
+		buf.Write(append(line, '\n'))
+	}
+
+	s.memory.mu.Unlock()
+
+	path := filepath.Join(s.dir, idempotencyFileName)
+	tmpPath := path + ".tmp"
+
+	if err := writeFileSync(tmpPath, buf.Bytes()); err != nil {
+		return fmt.Errorf("writing the idempotency keys failed: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing the idempotency keys failed: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+
+	if err != nil {
+		return fmt.Errorf("opening the idempotency keys failed: %w", err)
+	}
+
+	if s.log != nil {
+		s.log.Close()
+	}
+
+	s.log = f
+	s.logRecords = 0
+
+	return nil
+}
+
+func (s *FileIdempotencyStore) append(rec idempotencyLogRecord) error {
+	line, err := json.Marshal(rec)
+
+	if err != nil {
+		return fmt.Errorf("encoding the idempotency key failed: %w", err)
+	}
+
+	if _, err := s.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing the idempotency key failed: %w", err)
+	}
+
+	if err := s.log.Sync(); err != nil {
+		return fmt.Errorf("syncing the idempotency keys failed: %w", err)
+	}
+
+	s.logRecords++
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+)
+
+func testDeck(uuid string) *domain.Deck {
+	expiresAt := time.Date(2124, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := &domain.Deck{
+		UUID:     uuid,
+		Shuffled: true,
+		Cards:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts, DeckIndex: 1}, {Rank: domain.Five, Suit: domain.Spades, DeckIndex: 2}},
+		// Every field is set, so the tests check that all of them are stored.
+		ShuffleStrategy:  domain.SeededShuffle,
+		Seed:             42,
+		CutCardRemaining: 1,
+		Fairness:         &domain.Fairness{ServerSeed: "server", ClientSeed: "client", Commitment: domain.Commit("server")},
+		Visibility:       domain.Visibility{Mode: domain.FaceDown},
+		UndoDepth:        5,
+		Owner:            "alice",
+		Tags:             []string{"blackjack", "table-1"},
+		CreatedAt:        time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		UpdatedAt:        time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+		ExpiresAt:        &expiresAt,
+		Drawn:            []domain.Card{{Rank: domain.Ace, Suit: domain.Clubs}},
+		Piles: []*domain.Pile{
+			{Name: "discard", Cards: []domain.Card{{Rank: domain.King, Suit: domain.Diamonds}, domain.NewJoker()}},
+		},
+		Hands: []*domain.Hand{
+			{Player: "alice", Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Hearts}}},
+			{
+				Player:     "bob",
+				Cards:      []domain.Card{{Rank: domain.Three, Suit: domain.Hearts}},
+				Visibility: domain.Visibility{Mode: domain.VisibleToPlayers, Players: []string{"bob", "carol"}},
+			},
+		},
+		Operations: []domain.Operation{
+			{Type: domain.CutOperation, Index: 1},
+			{Type: domain.RiffleOperation, Times: 7, Seed: 3},
+			{Type: domain.ReshuffleOperation, Strategy: domain.SeededShuffle, Seed: 5},
+		},
+	}
+	d.Record(domain.CreatedEvent, d.CreatedAt, "request-1", "alice")
+	return d
+}
+
+func openFileRepository(t *testing.T, dir string, opts ...repository.FileOption) *repository.FileDeckRepository {
+	t.Helper()
+	r, err := repository.NewFileDeckRepository(dir, opts...)
+	if err != nil {
+		t.Fatalf("NewFileDeckRepository() error = %v", err)
+	}
+	return r
+}
+
+func TestFileDeckRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("recovers the decks from the log after a crash", func(t *testing.T) {
+		dir := t.TempDir()
+		r := openFileRepository(t, dir)
+		d := testDeck("deck-1")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("FileDeckRepository.Save() error = %v", err)
+		}
+		d.Draw(1)
+		d.Record(domain.DrawnEvent, time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC), "request-2", "bob")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("FileDeckRepository.Save() error = %v", err)
+		}
+
+		// The repository isn't closed, simulating a crash before any snapshot.
+		got, err := openFileRepository(t, dir).Get(ctx, "deck-1")
+		if err != nil {
+			t.Fatalf("FileDeckRepository.Get() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, d) {
+			t.Errorf("FileDeckRepository.Get() = %v, want %v", got, d)
+		}
+	})
+
+	t.Run("stores the history apart from the decks", func(t *testing.T) {
+		dir := t.TempDir()
+		testHistory(t, openFileRepository(t, dir, repository.SnapshotEvery(2)))
+
+		history, err := openFileRepository(t, dir).History(ctx, "kept")
+		if err != nil {
+			t.Fatalf("FileDeckRepository.History() after reopening error = %v", err)
+		}
+		if len(history) != 2 {
+			t.Errorf("FileDeckRepository.History() after reopening = %v, want 2 events", history)
+		}
+	})
+
+	t.Run("rejects stale saves", func(t *testing.T) {
+		testVersioning(t, openFileRepository(t, t.TempDir()))
+	})
+
+	t.Run("sweeps decks", func(t *testing.T) {
+		dir := t.TempDir()
+		testSweeping(t, openFileRepository(t, dir))
+
+		reopened := openFileRepository(t, dir)
+		if _, err := reopened.Get(ctx, "idle"); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("FileDeckRepository.Get() after reopening error = %v, want %v", err, domain.ErrDeckExpired)
+		}
+		if _, err := reopened.Get(ctx, "kept"); err != nil {
+			t.Errorf("FileDeckRepository.Get() after reopening error = %v", err)
+		}
+	})
+
+	t.Run("remembers the swept decks after compacting the log", func(t *testing.T) {
+		dir := t.TempDir()
+		r := openFileRepository(t, dir)
+		testSweeping(t, r)
+		if err := r.Close(); err != nil {
+			t.Fatalf("FileDeckRepository.Close() error = %v", err)
+		}
+
+		if _, err := openFileRepository(t, dir).Get(ctx, "idle"); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("FileDeckRepository.Get() after compacting error = %v, want %v", err, domain.ErrDeckExpired)
+		}
+	})
+
+	t.Run("loads the snapshots holding only the list of decks", func(t *testing.T) {
+		dir := t.TempDir()
+		data, err := json.Marshal([]*domain.Deck{testDeck("deck-1")})
+		if err != nil {
+			t.Fatalf("encoding the snapshot failed: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "decks.snapshot"), data, 0o644); err != nil {
+			t.Fatalf("writing the snapshot failed: %v", err)
+		}
+
+		if _, err := openFileRepository(t, dir).Get(ctx, "deck-1"); err != nil {
+			t.Errorf("FileDeckRepository.Get() error = %v", err)
+		}
+	})
+
+	t.Run("lists decks", func(t *testing.T) {
+		testListing(t, openFileRepository(t, t.TempDir()))
+	})
+
+	t.Run("deletes decks", func(t *testing.T) {
+		dir := t.TempDir()
+		testDeleting(t, openFileRepository(t, dir))
+
+		reopened := openFileRepository(t, dir)
+		if _, err := reopened.Get(ctx, "deleted"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("FileDeckRepository.Get() after reopening error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+		if _, err := reopened.Get(ctx, "kept"); err != nil {
+			t.Errorf("FileDeckRepository.Get() after reopening error = %v", err)
+		}
+	})
+
+	t.Run("recovers the decks from snapshots and the log", func(t *testing.T) {
+		dir := t.TempDir()
+		r := openFileRepository(t, dir, repository.SnapshotEvery(2))
+		for _, id := range []string{"deck-1", "deck-2", "deck-3"} {
+			if err := r.Save(ctx, testDeck(id)); err != nil {
+				t.Fatalf("FileDeckRepository.Save() error = %v", err)
+			}
+		}
+
+		reopened := openFileRepository(t, dir)
+		for _, id := range []string{"deck-1", "deck-2", "deck-3"} {
+			if _, err := reopened.Get(ctx, id); err != nil {
+				t.Errorf("FileDeckRepository.Get(%q) error = %v", id, err)
+			}
+		}
+		if err := reopened.Close(); err != nil {
+			t.Fatalf("FileDeckRepository.Close() error = %v", err)
+		}
+		if _, err := openFileRepository(t, dir).Get(ctx, "deck-3"); err != nil {
+			t.Errorf("FileDeckRepository.Get() after closing error = %v", err)
+		}
+	})
+
+	t.Run("keeps the saved decks when the snapshot fails", func(t *testing.T) {
+		dir := t.TempDir()
+		r := openFileRepository(t, dir, repository.SnapshotEvery(1))
+		// A directory in the way of the temporary snapshot makes writing it fail.
+		if err := os.Mkdir(filepath.Join(dir, "decks.snapshot.tmp"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		d := testDeck("deck-1")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("FileDeckRepository.Save() error = %v", err)
+		}
+		if err := r.Delete(ctx, "deck-1"); err != nil {
+			t.Fatalf("FileDeckRepository.Delete() error = %v", err)
+		}
+		if err := r.Save(ctx, testDeck("deck-2")); err != nil {
+			t.Fatalf("FileDeckRepository.Save() error = %v", err)
+		}
+
+		reopened := openFileRepository(t, dir)
+		if _, err := reopened.Get(ctx, "deck-1"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("FileDeckRepository.Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+		if _, err := reopened.Get(ctx, "deck-2"); err != nil {
+			t.Errorf("FileDeckRepository.Get() error = %v", err)
+		}
+	})
+
+	t.Run("discards an incomplete record at the end of the log", func(t *testing.T) {
+		dir := t.TempDir()
+		r := openFileRepository(t, dir)
+		if err := r.Save(ctx, testDeck("deck-1")); err != nil {
+			t.Fatalf("FileDeckRepository.Save() error = %v", err)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, "decks.wal"), os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(`{"op":"save","deck":{"uuid":"deck-2"`); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		reopened := openFileRepository(t, dir)
+		if _, err := reopened.Get(ctx, "deck-1"); err != nil {
+			t.Errorf("FileDeckRepository.Get() error = %v", err)
+		}
+		if _, err := reopened.Get(ctx, "deck-2"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("FileDeckRepository.Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+	})
+}
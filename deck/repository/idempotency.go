@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// purgeInterval is the minimum time between purges of the expired keys of an InMemoryIdempotencyStore.
+const purgeInterval = time.Minute
+
+// InMemoryIdempotencyStore represents a store of idempotency keys implemented using memory.
+// It is safe for concurrent use. Expired keys are purged from time to time while reserving keys.
+type InMemoryIdempotencyStore struct {
+	mu         sync.Mutex
+	records    map[service.IdempotencyKey]service.IdempotencyRecord
+	lastPurged time.Time
+}
+
+// NewInMemoryIdempotencyStore returns a new InMemoryIdempotencyStore.
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		records: make(map[service.IdempotencyKey]service.IdempotencyRecord),
+	}
+}
+
+// Reserve stores a record in progress for the key, unless the key has a record not expired at the given time,
+// which is returned instead.
+func (s *InMemoryIdempotencyStore) Reserve(_ context.Context, key service.IdempotencyKey, fingerprint string, now time.Time, ttl time.Duration) (service.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastPurged) >= purgeInterval {
+		s.purge(now)
+	}
+
+	if rec, ok := s.records[key]; ok && rec.ExpiresAt.After(now) {
+		return rec, false, nil
+	}
+
+	rec := service.IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+	s.records[key] = rec
+
+	return rec, true, nil
+}
+
+// Complete stores the response of the request that reserved the key. Does nothing if the key isn't reserved.
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, key service.IdempotencyKey, res service.IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[key]
+
+	if !ok {
+		return nil
+	}
+
+	rec.Response = &res
+	s.records[key] = rec
+
+	return nil
+}
+
+// Release forgets the key.
+func (s *InMemoryIdempotencyStore) Release(_ context.Context, key service.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+// purge deletes the records expired at the given time. The lock must be held.
+func (s *InMemoryIdempotencyStore) purge(now time.Time) {
+	for key, rec := range s.records {
+		if !rec.ExpiresAt.After(now) {
+			delete(s.records, key)
+		}
+	}
+
+	s.lastPurged = now
+}
@@ -0,0 +1,158 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// testIdempotencyStore checks that the store reserves, completes, releases and expires keys.
+func testIdempotencyStore(t *testing.T, s service.IdempotencyStore) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := service.IdempotencyKey{DeckID: "deck-1", Key: "key-1"}
+	res := service.IdempotentResponse{
+		Status: 200,
+		Header: map[string]string{"Content-Type": "application/json"},
+		Body:   []byte(`{"cards":[]}`),
+	}
+
+	if _, reserved, err := s.Reserve(ctx, key, "fingerprint-1", now, time.Hour); err != nil || !reserved {
+		t.Fatalf("Reserve() = %v, %v, want the key reserved", reserved, err)
+	}
+	got, reserved, err := s.Reserve(ctx, key, "fingerprint-2", now, time.Hour)
+	if err != nil || reserved || got.Fingerprint != "fingerprint-1" || got.Response != nil {
+		t.Errorf("Reserve() = %+v, %v, %v, want the record in progress", got, reserved, err)
+	}
+	other := service.IdempotencyKey{DeckID: "deck-2", Key: "key-1"}
+	if _, reserved, err := s.Reserve(ctx, other, "fingerprint-1", now, time.Hour); err != nil || !reserved {
+		t.Errorf("Reserve() other deck = %v, %v, want the key reserved", reserved, err)
+	}
+
+	if err := s.Complete(ctx, key, res); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	got, _, err = s.Reserve(ctx, key, "fingerprint-1", now.Add(time.Minute), time.Hour)
+	if err != nil || got.Response == nil || !reflect.DeepEqual(*got.Response, res) {
+		t.Errorf("Reserve() = %+v, %v, want the completed record", got, err)
+	}
+
+	if _, reserved, err := s.Reserve(ctx, key, "fingerprint-3", now.Add(time.Hour), time.Hour); err != nil || !reserved {
+		t.Errorf("Reserve() expired key = %v, %v, want the key reserved again", reserved, err)
+	}
+
+	if err := s.Release(ctx, other); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, reserved, err := s.Reserve(ctx, other, "fingerprint-2", now, time.Hour); err != nil || !reserved {
+		t.Errorf("Reserve() released key = %v, %v, want the key reserved again", reserved, err)
+	}
+}
+
+func TestInMemoryIdempotencyStore(t *testing.T) {
+	testIdempotencyStore(t, repository.NewInMemoryIdempotencyStore())
+}
+
+func TestSQLiteIdempotencyStore(t *testing.T) {
+	t.Run("stores the keys", func(t *testing.T) {
+		r := openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db"))
+		testIdempotencyStore(t, r.IdempotencyStore())
+	})
+	t.Run("releases the keys in progress after reopening", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		path := filepath.Join(t.TempDir(), "decks.db")
+		completed := service.IdempotencyKey{DeckID: "deck-1", Key: "key-1"}
+		inProgress := service.IdempotencyKey{DeckID: "deck-1", Key: "key-2"}
+		res := service.IdempotentResponse{Status: 201, Body: []byte(`{"deck_id":"deck-1"}`)}
+
+		r := openSQLiteRepository(t, path)
+		s := r.IdempotencyStore()
+		for _, key := range []service.IdempotencyKey{completed, inProgress} {
+			if _, reserved, err := s.Reserve(ctx, key, "fingerprint-1", now, time.Hour); err != nil || !reserved {
+				t.Fatalf("Reserve() = %v, %v, want the key reserved", reserved, err)
+			}
+		}
+		if err := s.Complete(ctx, completed, res); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if err := r.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		s = openSQLiteRepository(t, path).IdempotencyStore()
+
+		got, reserved, err := s.Reserve(ctx, completed, "fingerprint-2", now, time.Hour)
+		if err != nil || reserved || got.Response == nil || !reflect.DeepEqual(*got.Response, res) {
+			t.Errorf("Reserve() completed key = %+v, %v, %v, want the completed record", got, reserved, err)
+		}
+		if _, reserved, err := s.Reserve(ctx, inProgress, "fingerprint-2", now, time.Hour); err != nil || !reserved {
+			t.Errorf("Reserve() key in progress = %v, %v, want the key reserved again", reserved, err)
+		}
+	})
+}
+
+func TestFileIdempotencyStore(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("stores the keys", func(t *testing.T) {
+		s := openFileIdempotencyStore(t, t.TempDir(), now)
+		testIdempotencyStore(t, s)
+	})
+	t.Run("replays the completed keys after reopening", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		completed := service.IdempotencyKey{DeckID: "deck-1", Key: "key-1"}
+		inProgress := service.IdempotencyKey{DeckID: "deck-1", Key: "key-2"}
+		expiring := service.IdempotencyKey{DeckID: "deck-2", Key: "key-1"}
+		res := service.IdempotentResponse{Status: 201, Body: []byte(`{"deck_id":"deck-1"}`)}
+
+		s := openFileIdempotencyStore(t, dir, now)
+		for _, key := range []service.IdempotencyKey{completed, inProgress} {
+			if _, reserved, err := s.Reserve(ctx, key, "fingerprint-1", now, time.Hour); err != nil || !reserved {
+				t.Fatalf("Reserve() = %v, %v, want the key reserved", reserved, err)
+			}
+		}
+		if _, reserved, err := s.Reserve(ctx, expiring, "fingerprint-1", now, time.Minute); err != nil || !reserved {
+			t.Fatalf("Reserve() = %v, %v, want the key reserved", reserved, err)
+		}
+		for _, key := range []service.IdempotencyKey{completed, expiring} {
+			if err := s.Complete(ctx, key, res); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		later := now.Add(time.Minute)
+		s = openFileIdempotencyStore(t, dir, later)
+
+		got, reserved, err := s.Reserve(ctx, completed, "fingerprint-2", later, time.Hour)
+		want := service.IdempotencyRecord{Fingerprint: "fingerprint-1", Response: &res, ExpiresAt: now.Add(time.Hour)}
+		if err != nil || reserved || !reflect.DeepEqual(got, want) {
+			t.Errorf("Reserve() completed key = %+v, %v, %v, want %+v", got, reserved, err, want)
+		}
+		for _, key := range []service.IdempotencyKey{inProgress, expiring} {
+			if _, reserved, err := s.Reserve(ctx, key, "fingerprint-2", later, time.Hour); err != nil || !reserved {
+				t.Errorf("Reserve(%v) = %v, %v, want the key reserved again", key, reserved, err)
+			}
+		}
+	})
+}
+
+func openFileIdempotencyStore(t *testing.T, dir string, now time.Time) *repository.FileIdempotencyStore {
+	t.Helper()
+	s, err := repository.NewFileIdempotencyStore(dir, now)
+	if err != nil {
+		t.Fatalf("NewFileIdempotencyStore() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// Estimated sizes, in bytes, of the parts of a deck held in memory.
+const (
+	deckBaseSize  = 512
+	eventBaseSize = 128
+	cardSize      = int64(unsafe.Sizeof(domain.Card{}))
+)
+
+type lruOptions struct {
+	maxDecks int
+	maxBytes int64
+	backing  service.DeckRepository
+}
+
+// LRUOption is the interface implemented to allow options while creating a LRUDeckRepository.
+type LRUOption interface {
+	apply(*lruOptions)
+}
+
+type maxDecksOption int
+
+func (o maxDecksOption) apply(opts *lruOptions) {
+	opts.maxDecks = int(o)
+}
+
+// MaxDecks option to hold at most the given number of decks in memory.
+func MaxDecks(n int) LRUOption {
+	return maxDecksOption(n)
+}
+
+type maxBytesOption int64
+
+func (o maxBytesOption) apply(opts *lruOptions) {
+	opts.maxBytes = int64(o)
+}
+
+// MaxBytes option to hold at most the given estimated number of bytes of decks in memory.
+func MaxBytes(n int64) LRUOption {
+	return maxBytesOption(n)
+}
+
+type readThroughOption struct {
+	backing service.DeckRepository
+}
+
+func (o readThroughOption) apply(opts *lruOptions) {
+	opts.backing = o.backing
+}
+
+// ReadThrough option to use the repository as a cache in front of the given durable repository. The decks are
+// saved in the durable repository before being cached, and the decks missing from the cache are loaded from it.
+func ReadThrough(r service.DeckRepository) LRUOption {
+	return readThroughOption{backing: r}
+}
+
+// LRUStats are the counters of a LRUDeckRepository.
+type LRUStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Decks     int    `json:"decks"`
+	Bytes     int64  `json:"bytes"`
+}
+
+type lruEntry struct {
+	deck *domain.Deck
+	// history of the deck, only held without a durable repository.
+	history domain.History
+	size    int64
+}
+
+// lruLoad tracks the loads of a deck from the durable repository in progress.
+type lruLoad struct {
+	pending int
+	// deleted is whether the deck was deleted while being loaded, in which case the loaded deck isn't cached.
+	deleted bool
+}
+
+// LRUDeckRepository represents a repository of decks held in memory up to a maximum number of decks or estimated
+// bytes, evicting the least recently used decks when it is full. The most recently used deck is never evicted, even
+// if it is bigger than the maximum number of bytes.
+// On its own, evicted decks are lost. With the ReadThrough option it acts as a cache in front of a durable
+// repository instead, and evicted decks are loaded again from it when needed.
+// It is safe for concurrent use. Decks are copied when saved and retrieved.
+type LRUDeckRepository struct {
+	mu       sync.Mutex
+	maxDecks int
+	maxBytes int64
+	backing  service.DeckRepository
+	// order of the decks from the most to the least recently used. The values are *lruEntry.
+	order      *list.List
+	entries    map[string]*list.Element
+	tombstones map[string]time.Time
+	// loads are the loads from the durable repository in progress, by UUID.
+	loads map[string]*lruLoad
+	stats LRUStats
+}
+
+// NewLRUDeckRepository returns a new LRUDeckRepository. It is unbounded unless the options say otherwise.
+func NewLRUDeckRepository(opts ...LRUOption) *LRUDeckRepository {
+	var options lruOptions
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	return &LRUDeckRepository{
+		maxDecks:   options.maxDecks,
+		maxBytes:   options.maxBytes,
+		backing:    options.backing,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		tombstones: make(map[string]time.Time),
+		loads:      make(map[string]*lruLoad),
+	}
+}
+
+// Save saves the given deck in memory and increments its version. With a durable repository, the deck is saved
+// in it first. Returns an error if the version of the deck isn't the stored one or if the durable repository
+// couldn't save the deck.
+func (r *LRUDeckRepository) Save(ctx context.Context, d *domain.Deck) error {
+	if r.backing != nil {
+		return r.saveThrough(ctx, d)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		stored  int64
+		history domain.History
+	)
+
+	if e, ok := r.entries[d.UUID]; ok {
+		stored = e.Value.(*lruEntry).deck.Version
+		history = e.Value.(*lruEntry).history
+	}
+
+	if d.Version != stored {
+		return fmt.Errorf("%w: the deck is at version %d, not %d", domain.ErrVersionConflict, stored, d.Version)
+	}
+
+	d.Version++
+	r.put(d.Clone(), appendEvents(history, d.Pending))
+	d.Pending = nil
+
+	return nil
+}
+
+// saveThrough saves the deck in the durable repository and then caches it.
+func (r *LRUDeckRepository) saveThrough(ctx context.Context, d *domain.Deck) error {
+	err := r.backing.Save(ctx, d)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		// The cached deck may be the stale one, it is loaded again on the next get.
+		if errors.Is(err, domain.ErrVersionConflict) {
+			r.remove(d.UUID)
+		}
+
+		return err
+	}
+
+	r.put(d.Clone(), nil)
+
+	return nil
+}
+
+// Get gets the deck with the given UUID, marking it as the most recently used. With a durable repository, the decks
+// missing from memory are loaded from it. Returns an error if the deck is not found.
+func (r *LRUDeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
+	r.mu.Lock()
+
+	if e, ok := r.entries[uuid]; ok {
+		r.stats.Hits++
+		r.order.MoveToFront(e)
+		d := e.Value.(*lruEntry).deck.Clone()
+		r.mu.Unlock()
+
+		return d, nil
+	}
+
+	r.stats.Misses++
+
+	if r.backing == nil {
+		_, swept := r.tombstones[uuid]
+		r.mu.Unlock()
+
+		if swept {
+			return nil, domain.ErrDeckExpired
+		}
+
+		return nil, domain.ErrDeckNotFound
+	}
+
+	load, ok := r.loads[uuid]
+
+	if !ok {
+		load = &lruLoad{}
+		r.loads[uuid] = load
+	}
+
+	load.pending++
+	r.mu.Unlock()
+
+	d, err := r.backing.Get(ctx, uuid)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if load.pending--; load.pending == 0 {
+		delete(r.loads, uuid)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The deck may have been deleted, or a newer version saved, while loading it.
+	if e, ok := r.entries[uuid]; !load.deleted && (!ok || e.Value.(*lruEntry).deck.Version < d.Version) {
+		r.put(d.Clone(), nil)
+	}
+
+	return d, nil
+}
+
+// History returns the events of the deck with the given UUID, in the order they happened. With a durable repository,
+// they are retrieved from it. Returns an error if the deck is not found. Retrieving the history doesn't count as
+// using the deck.
+func (r *LRUDeckRepository) History(ctx context.Context, uuid string) (domain.History, error) {
+	if r.backing != nil {
+		return r.backing.History(ctx, uuid)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[uuid]
+
+	if !ok {
+		if _, swept := r.tombstones[uuid]; swept {
+			return nil, domain.ErrDeckExpired
+		}
+
+		return nil, domain.ErrDeckNotFound
+	}
+
+	return append(domain.History(nil), e.Value.(*lruEntry).history...), nil
+}
+
+// Delete deletes the deck with the given UUID. With a durable repository, it is deleted from it too.
+// Returns an error if the deck is not found.
+func (r *LRUDeckRepository) Delete(ctx context.Context, uuid string) error {
+	if r.backing != nil {
+		err := r.backing.Delete(ctx, uuid)
+
+		r.mu.Lock()
+		r.forget(uuid)
+		r.mu.Unlock()
+
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[uuid]; !ok {
+		if _, swept := r.tombstones[uuid]; swept {
+			return domain.ErrDeckExpired
+		}
+
+		return domain.ErrDeckNotFound
+	}
+
+	r.remove(uuid)
+
+	return nil
+}
+
+// List returns up to q.Limit summaries of the decks selected by the query, ordered by creation time and then UUID.
+// With a durable repository, the decks are listed from it, as the decks held in memory are only part of them.
+// Listing doesn't count as using the decks.
+func (r *LRUDeckRepository) List(ctx context.Context, q service.DeckQuery) ([]service.DeckSummary, error) {
+	if r.backing != nil {
+		return r.backing.List(ctx, q)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decks := make([]*domain.Deck, 0, len(r.entries))
+
+	for _, e := range r.entries {
+		decks = append(decks, e.Value.(*lruEntry).deck)
+	}
+
+	return listDecks(q, decks), nil
+}
+
+// Sweep deletes the decks sweepable at the given time and returns how many were deleted. With a durable repository,
+// they are deleted from it. Otherwise they are remembered for a week, so getting them returns domain.ErrDeckExpired.
+func (r *LRUDeckRepository) Sweep(ctx context.Context, now, idleBefore time.Time) (int, error) {
+	swept := 0
+
+	if r.backing != nil {
+		var err error
+
+		if swept, err = r.backing.Sweep(ctx, now, idleBefore); err != nil {
+			return 0, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The durable repository doesn't tell which decks it swept, so none of the decks being loaded is cached.
+	if r.backing != nil {
+		for _, load := range r.loads {
+			load.deleted = true
+		}
+	}
+
+	for uuid, e := range r.entries {
+		if !e.Value.(*lruEntry).deck.Sweepable(now, idleBefore) {
+			continue
+		}
+
+		r.remove(uuid)
+
+		if r.backing == nil {
+			r.tombstones[uuid] = now
+			swept++
+		}
+	}
+
+	for uuid, deletedAt := range r.tombstones {
+		if now.Sub(deletedAt) > tombstoneRetention {
+			delete(r.tombstones, uuid)
+		}
+	}
+
+	return swept, nil
+}
+
+// Stats returns the counters of the repository.
+func (r *LRUDeckRepository) Stats() LRUStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// put holds the deck without its pending events, along with its history, as the most recently used one, evicting the
+// least recently used decks if the repository is full. The lock must be held.
+func (r *LRUDeckRepository) put(d *domain.Deck, history domain.History) {
+	r.remove(d.UUID)
+
+	d.Pending = nil
+	entry := &lruEntry{deck: d, history: history, size: estimateSize(d, history)}
+	r.entries[d.UUID] = r.order.PushFront(entry)
+	r.stats.Decks++
+	r.stats.Bytes += entry.size
+
+	for r.order.Len() > 1 && r.full() {
+		r.remove(r.order.Back().Value.(*lruEntry).deck.UUID)
+		r.stats.Evictions++
+	}
+}
+
+// full returns whether the repository holds more decks or bytes than allowed. The lock must be held.
+func (r *LRUDeckRepository) full() bool {
+	return (r.maxDecks > 0 && r.stats.Decks > r.maxDecks) || (r.maxBytes > 0 && r.stats.Bytes > r.maxBytes)
+}
+
+// remove removes the deck with the given UUID from memory, if it is held. The lock must be held.
+func (r *LRUDeckRepository) remove(uuid string) {
+	e, ok := r.entries[uuid]
+
+	if !ok {
+		return
+	}
+
+	r.order.Remove(e)
+	delete(r.entries, uuid)
+	r.stats.Decks--
+	r.stats.Bytes -= e.Value.(*lruEntry).size
+}
+
+// forget removes the deleted deck from memory, keeping the loads of the deck in progress from caching it again.
+// The lock must be held.
+func (r *LRUDeckRepository) forget(uuid string) {
+	r.remove(uuid)
+
+	if load, ok := r.loads[uuid]; ok {
+		load.deleted = true
+	}
+}
+
+// estimateSize returns an estimation of the bytes held in memory by the deck and its history, counting the cards of
+// the deck along with the cards of the parameters and the checkpoint states of its events.
+func estimateSize(d *domain.Deck, history domain.History) int64 {
+	size := deckBaseSize + cardSize*int64(countCards(d))
+
+	for _, e := range history {
+		size += eventBaseSize
+
+		if e.Params != nil {
+			size += cardSize * int64(len(e.Params.Cards))
+		}
+
+		if e.State != nil {
+			size += deckBaseSize + cardSize*int64(countCards(e.State))
+		}
+	}
+
+	return size
+}
+
+// countCards returns the number of cards of the deck wherever they are.
+func countCards(d *domain.Deck) int {
+	n := len(d.Cards) + len(d.Drawn)
+
+	for _, p := range d.Piles {
+		n += len(p.Cards)
+	}
+
+	for _, h := range d.Hands {
+		n += len(h.Cards)
+	}
+
+	return n
+}
@@ -0,0 +1,188 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+)
+
+func TestLRUDeckRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("rejects stale saves", func(t *testing.T) {
+		testVersioning(t, repository.NewLRUDeckRepository())
+	})
+	t.Run("rejects stale saves reading through", func(t *testing.T) {
+		testVersioning(t, repository.NewLRUDeckRepository(repository.ReadThrough(repository.NewInMemoryDeckRepository())))
+	})
+	t.Run("sweeps decks", func(t *testing.T) {
+		testSweeping(t, repository.NewLRUDeckRepository())
+	})
+	t.Run("sweeps decks reading through", func(t *testing.T) {
+		testSweeping(t, repository.NewLRUDeckRepository(repository.ReadThrough(repository.NewInMemoryDeckRepository())))
+	})
+
+	t.Run("deletes decks", func(t *testing.T) {
+		testDeleting(t, repository.NewLRUDeckRepository())
+	})
+	t.Run("deletes decks reading through", func(t *testing.T) {
+		testDeleting(t, repository.NewLRUDeckRepository(repository.ReadThrough(repository.NewInMemoryDeckRepository())))
+	})
+
+	t.Run("lists decks", func(t *testing.T) {
+		testListing(t, repository.NewLRUDeckRepository())
+	})
+	t.Run("lists decks reading through", func(t *testing.T) {
+		testListing(t, repository.NewLRUDeckRepository(repository.MaxDecks(1), repository.ReadThrough(repository.NewInMemoryDeckRepository())))
+	})
+
+	t.Run("stores the history apart from the decks", func(t *testing.T) {
+		testHistory(t, repository.NewLRUDeckRepository())
+	})
+	t.Run("stores the history apart from the decks reading through", func(t *testing.T) {
+		testHistory(t, repository.NewLRUDeckRepository(repository.MaxDecks(1), repository.ReadThrough(repository.NewInMemoryDeckRepository())))
+	})
+
+	t.Run("evicts the least recently used decks", func(t *testing.T) {
+		r := repository.NewLRUDeckRepository(repository.MaxDecks(2))
+		for _, uuid := range []string{"a", "b"} {
+			if err := r.Save(ctx, testDeck(uuid)); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+		if _, err := r.Get(ctx, "a"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if err := r.Save(ctx, testDeck("c")); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		for uuid, want := range map[string]error{"a": nil, "b": domain.ErrDeckNotFound, "c": nil} {
+			if _, err := r.Get(ctx, uuid); !errors.Is(err, want) {
+				t.Errorf("Get(%q) error = %v, want %v", uuid, err, want)
+			}
+		}
+		got := r.Stats()
+		got.Bytes = 0
+		want := repository.LRUStats{Hits: 3, Misses: 1, Evictions: 1, Decks: 2}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Stats() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("evicts decks beyond the maximum bytes", func(t *testing.T) {
+		probe := repository.NewLRUDeckRepository()
+		if err := probe.Save(ctx, testDeck("probe")); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		size := probe.Stats().Bytes
+
+		r := repository.NewLRUDeckRepository(repository.MaxBytes(2*size + size/2))
+		for _, uuid := range []string{"a", "b", "c", "d"} {
+			if err := r.Save(ctx, testDeck(uuid)); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+
+		got := r.Stats()
+		want := repository.LRUStats{Evictions: 2, Decks: 2, Bytes: 2 * size}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Stats() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("keeps the newest deck even if it is too big", func(t *testing.T) {
+		r := repository.NewLRUDeckRepository(repository.MaxBytes(1))
+		if err := r.Save(ctx, testDeck("big")); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if _, err := r.Get(ctx, "big"); err != nil {
+			t.Errorf("Get() error = %v", err)
+		}
+	})
+
+	t.Run("loads the evicted decks reading through", func(t *testing.T) {
+		backing := repository.NewInMemoryDeckRepository()
+		r := repository.NewLRUDeckRepository(repository.MaxDecks(1), repository.ReadThrough(backing))
+		want := testDeck("a")
+		for _, d := range []*domain.Deck{want, testDeck("b")} {
+			if err := r.Save(ctx, d); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+
+		got, err := r.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Get() = %v, want %v", got, want)
+		}
+		if _, err := r.Get(ctx, "a"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if _, err := r.Get(ctx, "missing"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+
+		stats := r.Stats()
+		stats.Bytes = 0
+		wantStats := repository.LRUStats{Hits: 1, Misses: 2, Evictions: 2, Decks: 1}
+		if !reflect.DeepEqual(stats, wantStats) {
+			t.Errorf("Stats() = %+v, want %+v", stats, wantStats)
+		}
+	})
+	t.Run("doesn't cache the decks deleted while loading them", func(t *testing.T) {
+		backing := &blockingRepository{
+			InMemoryDeckRepository: repository.NewInMemoryDeckRepository(),
+			loading:                make(chan struct{}, 1),
+			resume:                 make(chan struct{}),
+		}
+		r := repository.NewLRUDeckRepository(repository.MaxDecks(1), repository.ReadThrough(backing))
+		for _, uuid := range []string{"a", "b"} {
+			if err := r.Save(ctx, testDeck(uuid)); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+
+		loaded := make(chan error)
+		go func() {
+			_, err := r.Get(ctx, "a")
+			loaded <- err
+		}()
+		<-backing.loading
+		if err := r.Delete(ctx, "a"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		close(backing.resume)
+		if err := <-loaded; err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+
+		if _, err := r.Get(ctx, "a"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("Get() after deleting error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+	})
+}
+
+// blockingRepository is an in-memory repository whose gets wait to be resumed after loading the deck, telling when
+// they are waiting.
+type blockingRepository struct {
+	*repository.InMemoryDeckRepository
+	loading chan struct{}
+	resume  chan struct{}
+}
+
+func (r *blockingRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
+	d, err := r.InMemoryDeckRepository.Get(ctx, uuid)
+	select {
+	case r.loading <- struct{}{}:
+	default:
+	}
+	<-r.resume
+	return d, err
+}
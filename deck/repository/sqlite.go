@@ -0,0 +1,996 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+
+	// Registers the pure Go SQLite driver, so the repository doesn't require cgo.
+	_ "modernc.org/sqlite"
+)
+
+// migration represents a versioned change of the database schema.
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations contains every schema change ordered by version. Applied migrations must never be modified,
+// new changes must be added as a new migration at the end.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE decks (
+				uuid TEXT PRIMARY KEY,
+				shuffled INTEGER NOT NULL
+			)`,
+			`CREATE TABLE deck_cards (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				position INTEGER NOT NULL,
+				code TEXT NOT NULL,
+				PRIMARY KEY (deck_uuid, position)
+			)`,
+		},
+	},
+	{
+		version: 2,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN seed INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 3,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN server_seed TEXT`,
+			`ALTER TABLE decks ADD COLUMN client_seed TEXT`,
+			`ALTER TABLE decks ADD COLUMN commitment TEXT`,
+		},
+	},
+	{
+		version: 4,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN shuffle_strategy TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 5,
+		statements: []string{
+			`ALTER TABLE deck_cards ADD COLUMN deck_index INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE decks ADD COLUMN cut_card_remaining INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 6,
+		statements: []string{
+			`CREATE TABLE deck_cards_new (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				location TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				code TEXT NOT NULL,
+				deck_index INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (deck_uuid, location, position)
+			)`,
+			`INSERT INTO deck_cards_new (deck_uuid, location, position, code, deck_index)
+				SELECT deck_uuid, 'stack', position, code, deck_index FROM deck_cards`,
+			`DROP TABLE deck_cards`,
+			`ALTER TABLE deck_cards_new RENAME TO deck_cards`,
+			`CREATE TABLE deck_piles (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				PRIMARY KEY (deck_uuid, name)
+			)`,
+		},
+	},
+	{
+		version: 7,
+		statements: []string{
+			`CREATE TABLE deck_operations (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				position INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				cut_index INTEGER NOT NULL DEFAULT 0,
+				times INTEGER NOT NULL DEFAULT 0,
+				seed INTEGER NOT NULL DEFAULT 0,
+				strategy TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (deck_uuid, position)
+			)`,
+		},
+	},
+	{
+		version: 8,
+		statements: []string{
+			`CREATE TABLE deck_hands (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				player TEXT NOT NULL,
+				position INTEGER NOT NULL,
+				PRIMARY KEY (deck_uuid, player)
+			)`,
+		},
+	},
+	{
+		version: 9,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN visibility_mode TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE decks ADD COLUMN visibility_players TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE deck_piles ADD COLUMN visibility_mode TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE deck_piles ADD COLUMN visibility_players TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE deck_hands ADD COLUMN visibility_mode TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE deck_hands ADD COLUMN visibility_players TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 10,
+		statements: []string{
+			`CREATE TABLE deck_events (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				number INTEGER NOT NULL,
+				type TEXT NOT NULL,
+				at TEXT NOT NULL,
+				request_id TEXT NOT NULL DEFAULT '',
+				player TEXT NOT NULL DEFAULT '',
+				state TEXT NOT NULL,
+				PRIMARY KEY (deck_uuid, number)
+			)`,
+		},
+	},
+	{
+		version: 11,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN undo_depth INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE deck_events ADD COLUMN reverted_to INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 12,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		version: 13,
+		statements: []string{
+			`CREATE TABLE idempotency_keys (
+				deck_uuid TEXT NOT NULL,
+				key TEXT NOT NULL,
+				fingerprint TEXT NOT NULL,
+				response TEXT,
+				expires_at INTEGER NOT NULL,
+				PRIMARY KEY (deck_uuid, key)
+			)`,
+			`CREATE INDEX idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+		},
+	},
+	{
+		version: 14,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE decks ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE decks ADD COLUMN expires_at INTEGER`,
+			`CREATE TABLE deck_tombstones (
+				uuid TEXT PRIMARY KEY,
+				deleted_at INTEGER NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 15,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN closed_at INTEGER`,
+		},
+	},
+	{
+		version: 16,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN owner TEXT NOT NULL DEFAULT ''`,
+			`CREATE TABLE deck_tags (
+				deck_uuid TEXT NOT NULL REFERENCES decks (uuid) ON DELETE CASCADE,
+				tag TEXT NOT NULL,
+				PRIMARY KEY (deck_uuid, tag)
+			)`,
+			`CREATE INDEX deck_tags_tag ON deck_tags (tag)`,
+			`CREATE INDEX decks_created_at ON decks (created_at, uuid)`,
+			`CREATE INDEX decks_owner ON decks (owner, created_at, uuid)`,
+		},
+	},
+	{
+		version: 17,
+		statements: []string{
+			`ALTER TABLE decks ADD COLUMN last_event INTEGER NOT NULL DEFAULT 0`,
+			`UPDATE decks SET last_event = (SELECT COUNT(*) FROM deck_events WHERE deck_events.deck_uuid = decks.uuid)`,
+			`ALTER TABLE deck_events ADD COLUMN params TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+}
+
+// Locations of the cards in the deck_cards table. The cards of a pile are located in the pile prefix followed
+// by the pile name, and the cards of a hand in the hand prefix followed by the player name.
+const (
+	stackLocation      = "stack"
+	drawnLocation      = "drawn"
+	pileLocationPrefix = "pile:"
+	handLocationPrefix = "hand:"
+)
+
+// SQLiteDeckRepository represents a repository of decks stored in a SQLite database.
+// Decks are stored in the decks table, their piles in the deck_piles table, their hands in the deck_hands table,
+// their operations in the deck_operations table, their tags in the deck_tags table and their cards, in order and
+// along with their location, in the deck_cards table. Their events are stored in the deck_events table, with their
+// parameters and their checkpoint states encoded as JSON. The decks swept are remembered in the deck_tombstones table.
+type SQLiteDeckRepository struct {
+	db *sql.DB
+}
+
+// sqlitePragmas are set on every connection to the database: foreign keys are enforced, the log is written ahead
+// and the connections wait for the locks held by others instead of failing right away.
+var sqlitePragmas = []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+
+// NewSQLiteDeckRepository opens the SQLite database with the given data source name, applies the pending schema
+// migrations and releases the idempotency keys left in progress. Returns an error if the database can't be opened
+// or migrated.
+func NewSQLiteDeckRepository(ctx context.Context, dsn string) (*SQLiteDeckRepository, error) {
+	db, err := sql.Open("sqlite", withPragmas(dsn))
+
+	if err != nil {
+		return nil, fmt.Errorf("opening the database failed: %w", err)
+	}
+
+	// SQLite allows a single writer, using a single connection avoids busy errors between the app's own connections.
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating the database failed: %w", err)
+	}
+
+	if err := releaseIdempotencyKeys(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteDeckRepository{db: db}, nil
+}
+
+// withPragmas returns the data source name setting the sqlitePragmas, so every connection opened with it gets them.
+func withPragmas(dsn string) string {
+	separator := "?"
+
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	for _, p := range sqlitePragmas {
+		dsn += separator + "_pragma=" + p
+		separator = "&"
+	}
+
+	return dsn
+}
+
+// migrate applies in order every migration with a version greater than the current schema version.
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+
+	if err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := applyMigration(ctx, db, m); err != nil {
+			return fmt.Errorf("applying migration %d failed: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, stmt := range m.statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", m.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Save saves the given deck, replaces its cards and inserts its pending events in a single transaction, then
+// increments the version of the deck and clears its pending events. Returns an error if the version of the deck isn't
+// the stored one or if the deck couldn't be written.
+func (r *SQLiteDeckRepository) Save(ctx context.Context, d *domain.Deck) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if err := checkVersion(ctx, tx, d); err != nil {
+		return err
+	}
+
+	if err := saveDeckRow(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the deck failed: %w", err)
+	}
+
+	if err := savePiles(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the piles failed: %w", err)
+	}
+
+	if err := saveHands(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the hands failed: %w", err)
+	}
+
+	if err := saveCards(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the cards failed: %w", err)
+	}
+
+	if err := saveOperations(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the operations failed: %w", err)
+	}
+
+	if err := saveTags(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the tags failed: %w", err)
+	}
+
+	if err := saveEvents(ctx, tx, d); err != nil {
+		return fmt.Errorf("saving the events failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	d.Version++
+	d.Pending = nil
+
+	return nil
+}
+
+// checkVersion returns an error if the version of the deck isn't the stored one, 0 if the deck isn't stored.
+func checkVersion(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	var stored int64
+
+	err := tx.QueryRowContext(ctx, "SELECT version FROM decks WHERE uuid = ?", d.UUID).Scan(&stored)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("getting the version of the deck failed: %w", err)
+	}
+
+	if d.Version != stored {
+		return fmt.Errorf("%w: the deck is at version %d, not %d", domain.ErrVersionConflict, stored, d.Version)
+	}
+
+	return nil
+}
+
+func saveDeckRow(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	var (
+		serverSeed, clientSeed, commitment sql.NullString
+		expiresAt, closedAt                sql.NullInt64
+	)
+
+	if d.ExpiresAt != nil {
+		expiresAt = sql.NullInt64{Int64: unixNano(*d.ExpiresAt), Valid: true}
+	}
+
+	if d.ClosedAt != nil {
+		closedAt = sql.NullInt64{Int64: unixNano(*d.ClosedAt), Valid: true}
+	}
+
+	if d.Fairness != nil {
+		serverSeed = sql.NullString{String: d.Fairness.ServerSeed, Valid: true}
+		clientSeed = sql.NullString{String: d.Fairness.ClientSeed, Valid: true}
+		commitment = sql.NullString{String: d.Fairness.Commitment, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO decks (uuid, shuffled, shuffle_strategy, seed, server_seed, client_seed,
+			commitment, cut_card_remaining, visibility_mode, visibility_players, undo_depth, version, created_at,
+			updated_at, expires_at, closed_at, owner, last_event)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (uuid) DO UPDATE SET shuffled = excluded.shuffled, shuffle_strategy = excluded.shuffle_strategy,
+			seed = excluded.seed, server_seed = excluded.server_seed, client_seed = excluded.client_seed,
+			commitment = excluded.commitment, cut_card_remaining = excluded.cut_card_remaining,
+			visibility_mode = excluded.visibility_mode, visibility_players = excluded.visibility_players,
+			undo_depth = excluded.undo_depth, version = excluded.version, created_at = excluded.created_at,
+			updated_at = excluded.updated_at, expires_at = excluded.expires_at, closed_at = excluded.closed_at,
+			owner = excluded.owner, last_event = excluded.last_event`,
+		d.UUID, d.Shuffled, d.ShuffleStrategy, d.Seed, serverSeed, clientSeed, commitment, d.CutCardRemaining,
+		d.Visibility.Mode, joinPlayers(d.Visibility.Players), d.UndoDepth, d.Version+1, unixNano(d.CreatedAt),
+		unixNano(d.UpdatedAt), expiresAt, closedAt, d.Owner, d.LastEvent)
+
+	return err
+}
+
+func savePiles(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_piles WHERE deck_uuid = ?", d.UUID); err != nil {
+		return err
+	}
+
+	for i, p := range d.Piles {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO deck_piles (deck_uuid, name, position, visibility_mode,
+				visibility_players)
+			VALUES (?, ?, ?, ?, ?)`, d.UUID, p.Name, i, p.Visibility.Mode, joinPlayers(p.Visibility.Players)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func saveHands(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_hands WHERE deck_uuid = ?", d.UUID); err != nil {
+		return err
+	}
+
+	for i, h := range d.Hands {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO deck_hands (deck_uuid, player, position, visibility_mode,
+				visibility_players)
+			VALUES (?, ?, ?, ?, ?)`, d.UUID, h.Player, i, h.Visibility.Mode, joinPlayers(h.Visibility.Players)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func saveOperations(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_operations WHERE deck_uuid = ?", d.UUID); err != nil {
+		return err
+	}
+
+	for i, op := range d.Operations {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO deck_operations (deck_uuid, position, type, cut_index, times, seed,
+				strategy)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`, d.UUID, i, op.Type, op.Index, op.Times, op.Seed, op.Strategy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func saveTags(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_tags WHERE deck_uuid = ?", d.UUID); err != nil {
+		return err
+	}
+
+	for _, tag := range d.Tags {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO deck_tags (deck_uuid, tag) VALUES (?, ?)", d.UUID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveEvents inserts the pending events of the deck. Events are immutable, so the stored ones are kept.
+// The events without parameters are stored with empty parameters, and the ones without state with a null state.
+func saveEvents(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	for _, e := range d.Pending {
+		var params []byte
+
+		if e.Params != nil {
+			var err error
+
+			if params, err = json.Marshal(e.Params); err != nil {
+				return fmt.Errorf("encoding the parameters of the event %d failed: %w", e.Number, err)
+			}
+		}
+
+		state, err := json.Marshal(e.State)
+
+		if err != nil {
+			return fmt.Errorf("encoding the state of the event %d failed: %w", e.Number, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO deck_events (deck_uuid, number, type, at, request_id, player,
+				reverted_to, params, state)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, d.UUID, e.Number, e.Type, e.At.Format(time.RFC3339Nano), e.RequestID,
+			e.Player, e.RevertedTo, string(params), string(state)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveCards replaces every card of the deck with the current ones, keeping their location and order.
+func saveCards(ctx context.Context, tx *sql.Tx, d *domain.Deck) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_cards WHERE deck_uuid = ?", d.UUID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO deck_cards (deck_uuid, location, position, code, deck_index)
+		VALUES (?, ?, ?, ?, ?)`)
+
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	locations := map[string][]domain.Card{
+		stackLocation: d.Cards,
+		drawnLocation: d.Drawn,
+	}
+
+	for _, p := range d.Piles {
+		locations[pileLocationPrefix+p.Name] = p.Cards
+	}
+
+	for _, h := range d.Hands {
+		locations[handLocationPrefix+h.Player] = h.Cards
+	}
+
+	for location, cards := range locations {
+		for i, c := range cards {
+			if _, err := stmt.ExecContext(ctx, d.UUID, location, i, c.Code(), c.DeckIndex); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get gets the deck with the given UUID with its cards in order. Returns an error if the deck is not found.
+func (r *SQLiteDeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
+	d, err := r.getDeckRow(ctx, uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.getPiles(ctx, d); err != nil {
+		return nil, fmt.Errorf("getting the piles failed: %w", err)
+	}
+
+	if err := r.getHands(ctx, d); err != nil {
+		return nil, fmt.Errorf("getting the hands failed: %w", err)
+	}
+
+	if err := r.getCards(ctx, d); err != nil {
+		return nil, fmt.Errorf("getting the cards failed: %w", err)
+	}
+
+	if err := r.getOperations(ctx, d); err != nil {
+		return nil, fmt.Errorf("getting the operations failed: %w", err)
+	}
+
+	if d.Tags, err = r.getTags(ctx, d.UUID); err != nil {
+		return nil, fmt.Errorf("getting the tags failed: %w", err)
+	}
+
+	return d, nil
+}
+
+func (r *SQLiteDeckRepository) getDeckRow(ctx context.Context, uuid string) (*domain.Deck, error) {
+	d := &domain.Deck{UUID: uuid}
+
+	var (
+		serverSeed, clientSeed, commitment sql.NullString
+		visibilityPlayers                  string
+		createdAt, updatedAt               int64
+		expiresAt, closedAt                sql.NullInt64
+	)
+
+	err := r.db.QueryRowContext(ctx, `SELECT shuffled, shuffle_strategy, seed, server_seed, client_seed, commitment,
+			cut_card_remaining, visibility_mode, visibility_players, undo_depth, version, created_at, updated_at,
+			expires_at, closed_at, owner, last_event
+		FROM decks WHERE uuid = ?`, uuid).Scan(&d.Shuffled, &d.ShuffleStrategy, &d.Seed, &serverSeed, &clientSeed,
+		&commitment, &d.CutCardRemaining, &d.Visibility.Mode, &visibilityPlayers, &d.UndoDepth, &d.Version,
+		&createdAt, &updatedAt, &expiresAt, &closedAt, &d.Owner, &d.LastEvent)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.notFound(ctx, uuid)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("getting the deck failed: %w", err)
+	}
+
+	d.CreatedAt = fromUnixNano(createdAt)
+	d.UpdatedAt = fromUnixNano(updatedAt)
+
+	if expiresAt.Valid {
+		t := fromUnixNano(expiresAt.Int64)
+		d.ExpiresAt = &t
+	}
+
+	if closedAt.Valid {
+		t := fromUnixNano(closedAt.Int64)
+		d.ClosedAt = &t
+	}
+
+	d.Visibility.Players = splitPlayers(visibilityPlayers)
+
+	if serverSeed.Valid {
+		d.Fairness = &domain.Fairness{
+			ServerSeed: serverSeed.String,
+			ClientSeed: clientSeed.String,
+			Commitment: commitment.String,
+		}
+	}
+
+	return d, nil
+}
+
+func (r *SQLiteDeckRepository) getPiles(ctx context.Context, d *domain.Deck) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT name, visibility_mode, visibility_players FROM deck_piles
+		WHERE deck_uuid = ? ORDER BY position`, d.UUID)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &domain.Pile{Cards: []domain.Card{}}
+
+		var visibilityPlayers string
+
+		if err := rows.Scan(&p.Name, &p.Visibility.Mode, &visibilityPlayers); err != nil {
+			return err
+		}
+
+		p.Visibility.Players = splitPlayers(visibilityPlayers)
+
+		d.Piles = append(d.Piles, p)
+	}
+
+	return rows.Err()
+}
+
+func (r *SQLiteDeckRepository) getHands(ctx context.Context, d *domain.Deck) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT player, visibility_mode, visibility_players FROM deck_hands
+		WHERE deck_uuid = ? ORDER BY position`, d.UUID)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		h := &domain.Hand{Cards: []domain.Card{}}
+
+		var visibilityPlayers string
+
+		if err := rows.Scan(&h.Player, &h.Visibility.Mode, &visibilityPlayers); err != nil {
+			return err
+		}
+
+		h.Visibility.Players = splitPlayers(visibilityPlayers)
+
+		d.Hands = append(d.Hands, h)
+	}
+
+	return rows.Err()
+}
+
+func (r *SQLiteDeckRepository) getOperations(ctx context.Context, d *domain.Deck) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT type, cut_index, times, seed, strategy FROM deck_operations
+		WHERE deck_uuid = ? ORDER BY position`, d.UUID)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var op domain.Operation
+
+		if err := rows.Scan(&op.Type, &op.Index, &op.Times, &op.Seed, &op.Strategy); err != nil {
+			return err
+		}
+
+		d.Operations = append(d.Operations, op)
+	}
+
+	return rows.Err()
+}
+
+// getTags returns the tags of the deck with the given UUID, sorted.
+func (r *SQLiteDeckRepository) getTags(ctx context.Context, uuid string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT tag FROM deck_tags WHERE deck_uuid = ? ORDER BY tag", uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tags []string
+
+	for rows.Next() {
+		var tag string
+
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// History returns the events of the deck with the given UUID, in the order they happened. Returns an error if the
+// deck is not found.
+func (r *SQLiteDeckRepository) History(ctx context.Context, uuid string) (domain.History, error) {
+	var exists bool
+
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM decks WHERE uuid = ?)", uuid).
+		Scan(&exists); err != nil {
+		return nil, fmt.Errorf("getting the deck failed: %w", err)
+	}
+
+	if !exists {
+		return nil, r.notFound(ctx, uuid)
+	}
+
+	history, err := r.getEvents(ctx, uuid)
+
+	if err != nil {
+		return nil, fmt.Errorf("getting the events failed: %w", err)
+	}
+
+	return history, nil
+}
+
+// getEvents returns the events of the deck with the given UUID. The rows are closed before returning, as the database
+// has a single connection.
+func (r *SQLiteDeckRepository) getEvents(ctx context.Context, uuid string) (domain.History, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT number, type, at, request_id, player, reverted_to, params, state
+		FROM deck_events WHERE deck_uuid = ? ORDER BY number`, uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var history domain.History
+
+	for rows.Next() {
+		var (
+			e             domain.Event
+			at            string
+			params, state string
+		)
+
+		if err := rows.Scan(&e.Number, &e.Type, &at, &e.RequestID, &e.Player, &e.RevertedTo, &params,
+			&state); err != nil {
+			return nil, err
+		}
+
+		if e.At, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("decoding the time of the event %d failed: %w", e.Number, err)
+		}
+
+		if params != "" {
+			if err := json.Unmarshal([]byte(params), &e.Params); err != nil {
+				return nil, fmt.Errorf("decoding the parameters of the event %d failed: %w", e.Number, err)
+			}
+		}
+
+		if err := json.Unmarshal([]byte(state), &e.State); err != nil {
+			return nil, fmt.Errorf("decoding the state of the event %d failed: %w", e.Number, err)
+		}
+
+		history = append(history, e)
+	}
+
+	return history, rows.Err()
+}
+
+// getCards sets the cards of the deck in every location. The piles and the hands of the deck must be already set.
+func (r *SQLiteDeckRepository) getCards(ctx context.Context, d *domain.Deck) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT location, code, deck_index FROM deck_cards
+		WHERE deck_uuid = ? ORDER BY location, position`, d.UUID)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	d.Cards = []domain.Card{}
+
+	for rows.Next() {
+		var (
+			location  string
+			code      string
+			deckIndex int
+		)
+
+		if err := rows.Scan(&location, &code, &deckIndex); err != nil {
+			return err
+		}
+
+		c, err := domain.FromCode(code)
+
+		if err != nil {
+			return fmt.Errorf("decoding the card %q failed: %w", code, err)
+		}
+
+		c.DeckIndex = deckIndex
+
+		switch {
+		case location == stackLocation:
+			d.Cards = append(d.Cards, c)
+		case location == drawnLocation:
+			d.Drawn = append(d.Drawn, c)
+		case strings.HasPrefix(location, pileLocationPrefix):
+			p, err := d.Pile(strings.TrimPrefix(location, pileLocationPrefix))
+
+			if err != nil {
+				return err
+			}
+
+			p.Cards = append(p.Cards, c)
+		case strings.HasPrefix(location, handLocationPrefix):
+			h, err := d.Hand(strings.TrimPrefix(location, handLocationPrefix))
+
+			if err != nil {
+				return err
+			}
+
+			h.Cards = append(h.Cards, c)
+		default:
+			return fmt.Errorf("unknown card location %q", location)
+		}
+	}
+
+	return rows.Err()
+}
+
+// notFound returns the error for a deck that isn't stored, domain.ErrDeckExpired if it was swept.
+func (r *SQLiteDeckRepository) notFound(ctx context.Context, uuid string) error {
+	var swept bool
+
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM deck_tombstones WHERE uuid = ?)", uuid).
+		Scan(&swept); err != nil {
+		return fmt.Errorf("getting the tombstone of the deck failed: %w", err)
+	}
+
+	if swept {
+		return domain.ErrDeckExpired
+	}
+
+	return domain.ErrDeckNotFound
+}
+
+// sweepableCondition selects the decks expired at the first parameter, or exhausted or closed and not updated after
+// the second.
+const sweepableCondition = `expires_at <= ? OR (updated_at <= ? AND (closed_at IS NOT NULL OR NOT EXISTS (
+	SELECT 1 FROM deck_cards WHERE deck_cards.deck_uuid = decks.uuid AND deck_cards.location = '` + stackLocation + `')))`
+
+// Sweep deletes the decks sweepable at the given time along with everything they hold, and returns how many were
+// deleted. The deleted decks are remembered for a week in the deck_tombstones table, so getting them returns
+// domain.ErrDeckExpired.
+func (r *SQLiteDeckRepository) Sweep(ctx context.Context, now, idleBefore time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO deck_tombstones (uuid, deleted_at)
+		SELECT uuid, ? FROM decks WHERE `+sweepableCondition, unixNano(now), unixNano(now), unixNano(idleBefore)); err != nil {
+		return 0, fmt.Errorf("saving the tombstones failed: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM decks WHERE "+sweepableCondition, unixNano(now), unixNano(idleBefore))
+
+	if err != nil {
+		return 0, fmt.Errorf("deleting the decks failed: %w", err)
+	}
+
+	swept, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM deck_tombstones WHERE deleted_at < ?",
+		unixNano(now.Add(-tombstoneRetention))); err != nil {
+		return 0, fmt.Errorf("purging the tombstones failed: %w", err)
+	}
+
+	return int(swept), tx.Commit()
+}
+
+// Delete deletes the deck with the given UUID along with everything it holds. Returns an error if the deck is not
+// found.
+func (r *SQLiteDeckRepository) Delete(ctx context.Context, uuid string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM decks WHERE uuid = ?", uuid)
+
+	if err != nil {
+		return fmt.Errorf("deleting the deck failed: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return r.notFound(ctx, uuid)
+	}
+
+	return nil
+}
+
+// unixNano returns the time as nanoseconds since the Unix epoch, 0 for the zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// fromUnixNano returns the time for the nanoseconds since the Unix epoch, the zero time for 0.
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, n).UTC()
+}
+
+// joinPlayers encodes the players of a visibility in a single column. Player names can't contain commas.
+func joinPlayers(players []string) string {
+	return strings.Join(players, ",")
+}
+
+func splitPlayers(players string) []string {
+	if players == "" {
+		return nil
+	}
+
+	return strings.Split(players, ",")
+}
+
+// Close closes the database.
+func (r *SQLiteDeckRepository) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// SQLiteIdempotencyStore represents a store of idempotency keys in the idempotency_keys table of the database of a
+// SQLiteDeckRepository, with their responses encoded as JSON. Expired keys are purged while reserving keys, and the
+// keys in progress are released when the database is opened.
+type SQLiteIdempotencyStore struct {
+	db *sql.DB
+}
+
+// IdempotencyStore returns a store of idempotency keys sharing the database of the repository.
+func (r *SQLiteDeckRepository) IdempotencyStore() *SQLiteIdempotencyStore {
+	return &SQLiteIdempotencyStore{db: r.db}
+}
+
+// releaseIdempotencyKeys forgets the keys still in progress when the database is opened. Their requests were
+// interrupted by a restart, so they can be retried instead of answering as in progress until the keys expire.
+func releaseIdempotencyKeys(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE response IS NULL"); err != nil {
+		return fmt.Errorf("releasing the keys in progress failed: %w", err)
+	}
+
+	return nil
+}
+
+// Reserve stores a record in progress for the key, unless the key has a record not expired at the given time,
+// which is returned instead.
+func (s *SQLiteIdempotencyStore) Reserve(ctx context.Context, key service.IdempotencyKey, fingerprint string, now time.Time, ttl time.Duration) (service.IdempotencyRecord, bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return service.IdempotencyRecord{}, false, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ?", now.UnixNano()); err != nil {
+		return service.IdempotencyRecord{}, false, fmt.Errorf("purging the expired keys failed: %w", err)
+	}
+
+	rec := service.IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)}
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO idempotency_keys (deck_uuid, key, fingerprint, expires_at)
+		VALUES (?, ?, ?, ?) ON CONFLICT (deck_uuid, key) DO NOTHING`,
+		key.DeckID, key.Key, fingerprint, rec.ExpiresAt.UnixNano())
+
+	if err != nil {
+		return service.IdempotencyRecord{}, false, fmt.Errorf("reserving the key failed: %w", err)
+	}
+
+	inserted, err := res.RowsAffected()
+
+	if err != nil {
+		return service.IdempotencyRecord{}, false, err
+	}
+
+	if inserted == 0 {
+		if rec, err = getIdempotencyRecord(ctx, tx, key); err != nil {
+			return service.IdempotencyRecord{}, false, err
+		}
+	}
+
+	return rec, inserted == 1, tx.Commit()
+}
+
+func getIdempotencyRecord(ctx context.Context, tx *sql.Tx, key service.IdempotencyKey) (service.IdempotencyRecord, error) {
+	var (
+		rec       service.IdempotencyRecord
+		response  sql.NullString
+		expiresAt int64
+	)
+
+	if err := tx.QueryRowContext(ctx, `SELECT fingerprint, response, expires_at FROM idempotency_keys
+		WHERE deck_uuid = ? AND key = ?`, key.DeckID, key.Key).Scan(&rec.Fingerprint, &response, &expiresAt); err != nil {
+		return service.IdempotencyRecord{}, fmt.Errorf("getting the key failed: %w", err)
+	}
+
+	rec.ExpiresAt = time.Unix(0, expiresAt).UTC()
+
+	if response.Valid {
+		if err := json.Unmarshal([]byte(response.String), &rec.Response); err != nil {
+			return service.IdempotencyRecord{}, fmt.Errorf("decoding the response failed: %w", err)
+		}
+	}
+
+	return rec, nil
+}
+
+// Complete stores the response of the request that reserved the key. Does nothing if the key isn't reserved.
+func (s *SQLiteIdempotencyStore) Complete(ctx context.Context, key service.IdempotencyKey, res service.IdempotentResponse) error {
+	response, err := json.Marshal(res)
+
+	if err != nil {
+		return fmt.Errorf("encoding the response failed: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE idempotency_keys SET response = ? WHERE deck_uuid = ? AND key = ?",
+		string(response), key.DeckID, key.Key)
+
+	return err
+}
+
+// Release forgets the key.
+func (s *SQLiteIdempotencyStore) Release(ctx context.Context, key service.IdempotencyKey) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE deck_uuid = ? AND key = ?", key.DeckID, key.Key)
+
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// listQuery selects the summary of every deck along with its remaining cards, as the decks table d.
+const listQuery = `SELECT uuid, version, shuffled, owner, created_at, updated_at, expires_at, closed_at, remaining
+	FROM (SELECT decks.*, (SELECT COUNT(*) FROM deck_cards WHERE deck_cards.deck_uuid = decks.uuid
+		AND deck_cards.location = '` + stackLocation + `') AS remaining FROM decks) AS d`
+
+// List returns up to q.Limit summaries of the decks selected by the query, ordered by creation time and then UUID.
+func (r *SQLiteDeckRepository) List(ctx context.Context, q service.DeckQuery) ([]service.DeckSummary, error) {
+	conditions, args := listConditions(q)
+	query := listQuery
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	decks, err := r.listSummaries(ctx, query+" ORDER BY created_at, uuid LIMIT ?", append(args, q.Limit)...)
+
+	if err != nil {
+		return nil, fmt.Errorf("listing the decks failed: %w", err)
+	}
+
+	for i := range decks {
+		if decks[i].Tags, err = r.getTags(ctx, decks[i].DeckID); err != nil {
+			return nil, fmt.Errorf("getting the tags failed: %w", err)
+		}
+	}
+
+	return decks, nil
+}
+
+// listConditions returns the conditions selecting the decks of the query, along with their arguments.
+func listConditions(q service.DeckQuery) ([]string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	add := func(condition string, conditionArgs ...any) {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	f := q.Filter
+
+	if !f.CreatedAfter.IsZero() {
+		add("created_at > ?", unixNano(f.CreatedAfter))
+	}
+
+	if f.Shuffled != nil {
+		add("shuffled = ?", *f.Shuffled)
+	}
+
+	if f.MinRemaining != nil {
+		add("remaining >= ?", *f.MinRemaining)
+	}
+
+	if f.MaxRemaining != nil {
+		add("remaining <= ?", *f.MaxRemaining)
+	}
+
+	if f.Owner != "" {
+		add("owner = ?", f.Owner)
+	}
+
+	for _, tag := range f.Tags {
+		add("EXISTS (SELECT 1 FROM deck_tags WHERE deck_tags.deck_uuid = d.uuid AND deck_tags.tag = ?)", tag)
+	}
+
+	if c := q.After; c != nil {
+		createdAt := unixNano(c.CreatedAt)
+		add("(created_at > ? OR (created_at = ? AND uuid > ?))", createdAt, createdAt, c.UUID)
+	}
+
+	return conditions, args
+}
+
+// listSummaries returns the summaries of the decks selected by the query, without their tags. The rows are closed
+// before returning, as the database has a single connection.
+func (r *SQLiteDeckRepository) listSummaries(ctx context.Context, query string, args ...any) ([]service.DeckSummary, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var decks []service.DeckSummary
+
+	for rows.Next() {
+		var (
+			s                    service.DeckSummary
+			createdAt, updatedAt int64
+			expiresAt, closedAt  sql.NullInt64
+		)
+
+		if err := rows.Scan(&s.DeckID, &s.Version, &s.Shuffled, &s.Owner, &createdAt, &updatedAt, &expiresAt,
+			&closedAt, &s.Remaining); err != nil {
+			return nil, err
+		}
+
+		s.CreatedAt = fromUnixNano(createdAt)
+		s.UpdatedAt = fromUnixNano(updatedAt)
+
+		if expiresAt.Valid {
+			t := fromUnixNano(expiresAt.Int64)
+			s.ExpiresAt = &t
+		}
+
+		if closedAt.Valid {
+			t := fromUnixNano(closedAt.Int64)
+			s.ClosedAt = &t
+		}
+
+		decks = append(decks, s)
+	}
+
+	return decks, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+)
+
+func openSQLiteRepository(t *testing.T, path string) *repository.SQLiteDeckRepository {
+	t.Helper()
+	r, err := repository.NewSQLiteDeckRepository(context.Background(), path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDeckRepository() error = %v", err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestSQLiteDeckRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves and gets decks keeping the card order", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "decks.db")
+		r := openSQLiteRepository(t, path)
+		d := testDeck("deck-1")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("SQLiteDeckRepository.Save() error = %v", err)
+		}
+		d.Draw(1)
+		d.Record(domain.DrawnEvent, time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC), "request-2", "bob")
+		if err := r.Save(ctx, d); err != nil {
+			t.Fatalf("SQLiteDeckRepository.Save() error = %v", err)
+		}
+		r.Close()
+
+		got, err := openSQLiteRepository(t, path).Get(ctx, "deck-1")
+		if err != nil {
+			t.Fatalf("SQLiteDeckRepository.Get() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, d) {
+			t.Errorf("SQLiteDeckRepository.Get() = %v, want %v", got, d)
+		}
+	})
+
+	t.Run("rejects stale saves", func(t *testing.T) {
+		testVersioning(t, openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db")))
+	})
+
+	t.Run("sweeps decks", func(t *testing.T) {
+		testSweeping(t, openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db")))
+	})
+
+	t.Run("lists decks", func(t *testing.T) {
+		testListing(t, openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db")))
+	})
+
+	t.Run("stores the history apart from the decks", func(t *testing.T) {
+		testHistory(t, openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db")))
+	})
+
+	t.Run("deletes decks", func(t *testing.T) {
+		testDeleting(t, openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db")))
+	})
+
+	t.Run("returns an error if the deck is not found", func(t *testing.T) {
+		r := openSQLiteRepository(t, filepath.Join(t.TempDir(), "decks.db"))
+		if _, err := r.Get(ctx, "missing"); !errors.Is(err, domain.ErrDeckNotFound) {
+			t.Errorf("SQLiteDeckRepository.Get() error = %v, want %v", err, domain.ErrDeckNotFound)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// yieldingDeckRepository yields the processor after every access, so the goroutines interleave
+// between reading and saving a deck even with a single CPU.
+type yieldingDeckRepository struct {
+	*repository.InMemoryDeckRepository
+}
+
+func (r yieldingDeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
+	defer runtime.Gosched()
+	return r.InMemoryDeckRepository.Get(ctx, uuid)
+}
+
+// TestDeckService_DrawCardsConcurrently must be run with -race to detect unsynchronized accesses.
+func TestDeckService_DrawCardsConcurrently(t *testing.T) {
+	const (
+		decks      = 4
+		drawers    = 32
+		drawAmount = 1
+	)
+
+	ctx := context.Background()
+	s := service.NewDeckService(yieldingDeckRepository{repository.NewInMemoryDeckRepository()})
+
+	deckIDs := make([]string, decks)
+
+	for i := range deckIDs {
+		out, err := s.CreateDeck(ctx)
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		deckIDs[i] = out.DeckID
+	}
+
+	var (
+		mu    sync.Mutex
+		dealt = make(map[string][]domain.Card)
+		wg    sync.WaitGroup
+	)
+
+	for _, id := range deckIDs {
+		for i := 0; i < drawers; i++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				for {
+					out, err := s.DrawCards(ctx, id, drawAmount)
+					if err != nil {
+						t.Errorf("DeckService.DrawCards() error = %v", err)
+						return
+					}
+					if len(out.Cards) == 0 {
+						return
+					}
+					mu.Lock()
+					dealt[id] = append(dealt[id], out.Cards...)
+					mu.Unlock()
+				}
+			}(id)
+		}
+	}
+
+	wg.Wait()
+
+	for _, id := range deckIDs {
+		seen := make(map[domain.Card]bool)
+		for _, c := range dealt[id] {
+			if seen[c] {
+				t.Errorf("card %s from deck %s was dealt twice", c, id)
+			}
+			seen[c] = true
+		}
+		if len(seen) != 52 {
+			t.Errorf("deck %s dealt %d different cards, want 52", id, len(seen))
+		}
+	}
+}
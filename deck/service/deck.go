@@ -3,32 +3,148 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/cfagudelo96/toggle-test/deck/domain"
 )
 
+var (
+	// ErrInvalidOption error returned when the options given can't be combined.
+	ErrInvalidOption = errors.New("invalid_option")
+)
+
 // DeckRepository represents the interface required for storing and retrieving decks.
 type DeckRepository interface {
 	Save(ctx context.Context, d *domain.Deck) error
 	Get(ctx context.Context, uuid string) (*domain.Deck, error)
+	// Delete deletes the deck with the given UUID. Getting it afterwards returns domain.ErrDeckNotFound.
+	Delete(ctx context.Context, uuid string) error
+	// List returns up to q.Limit summaries of the decks selected by q.Filter that come after q.After, in the order
+	// described by DeckCursor.
+	List(ctx context.Context, q DeckQuery) ([]DeckSummary, error)
+	// Sweep deletes the decks sweepable at the given time, as told by domain.Deck.Sweepable, and returns how many
+	// were deleted. Getting a deleted deck afterwards returns domain.ErrDeckExpired.
+	Sweep(ctx context.Context, now, idleBefore time.Time) (int, error)
+	// History returns the events of the deck with the given UUID, stored apart from the deck by Save from its
+	// pending events. Returns the same errors as Get when the deck isn't stored.
+	History(ctx context.Context, uuid string) (domain.History, error)
 }
 
 // DeckService handles the deck related use cases.
+// It is safe for concurrent use, the operations modifying a deck are serialized per deck.
 type DeckService struct {
 	deckRepository DeckRepository
+	locks          *deckLocks
+	seedMu         sync.Mutex
+	seedSource     domain.SeedSource
+	now            func() time.Time
+	defaultTTL     time.Duration
+}
+
+type deckServiceOptions struct {
+	seedSource domain.SeedSource
+	now        func() time.Time
+	defaultTTL time.Duration
+}
+
+// DeckServiceOption is the interface implemented to allow options while creating a new DeckService.
+type DeckServiceOption interface {
+	apply(*deckServiceOptions)
+}
+
+type seedSourceOption struct {
+	source domain.SeedSource
+}
+
+func (c seedSourceOption) apply(o *deckServiceOptions) {
+	o.seedSource = c.source
+}
+
+// WithSeedSource option to take the seeds of the new decks from the given source.
+// The source doesn't need to be safe for concurrent use.
+func WithSeedSource(src domain.SeedSource) DeckServiceOption {
+	return seedSourceOption{source: src}
+}
+
+type clockOption func() time.Time
+
+func (c clockOption) apply(o *deckServiceOptions) {
+	o.now = c
+}
+
+// WithClock option to take the current time from the given function, used for the events and the expiration of
+// the decks.
+func WithClock(now func() time.Time) DeckServiceOption {
+	return clockOption(now)
+}
+
+type defaultTTLOption time.Duration
+
+func (c defaultTTLOption) apply(o *deckServiceOptions) {
+	o.defaultTTL = time.Duration(c)
 }
 
-// NewDeckService returns a new DeckService.
-func NewDeckService(r DeckRepository) *DeckService {
+// WithDefaultTTL option to make the new decks expire once the given time to live passes, unless they are created
+// with another one. By default decks never expire.
+func WithDefaultTTL(ttl time.Duration) DeckServiceOption {
+	return defaultTTLOption(ttl)
+}
+
+// NewDeckService returns a new DeckService. By default the seeds of the new decks are taken from
+// domain.DefaultSeedSource, the current time from time.Now and the decks never expire, unless the options say otherwise.
+func NewDeckService(r DeckRepository, opts ...DeckServiceOption) *DeckService {
+	options := deckServiceOptions{
+		seedSource: domain.DefaultSeedSource(),
+		now:        time.Now,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
 	return &DeckService{
 		deckRepository: r,
+		locks:          newDeckLocks(),
+		seedSource:     options.seedSource,
+		now:            options.now,
+		defaultTTL:     options.defaultTTL,
 	}
 }
 
+// nextSeed returns the next seed from the seed source of the service.
+func (s *DeckService) nextSeed() int64 {
+	s.seedMu.Lock()
+	defer s.seedMu.Unlock()
+
+	return s.seedSource.Int63()
+}
+
+// shufflerFor returns the shuffler used to reshuffle cards of the deck, following the strategy of the deck.
+func (s *DeckService) shufflerFor(d *domain.Deck) domain.Shuffler {
+	if d.ShuffleStrategy == domain.SecureShuffle {
+		return domain.SecureShuffler{}
+	}
+
+	return domain.SeededShuffler{Seed: s.nextSeed()}
+}
+
 type deckCreationOptions struct {
-	shuffled bool
-	cards    []domain.Card
+	shuffled   bool
+	visibility *domain.Visibility
+	strategy   domain.ShuffleStrategy
+	cards      []domain.Card
+	// composition of the deck, only used if no cards are given.
+	composition *domain.Composition
+	penetration *float64
+	seed        *int64
+	fair        bool
+	clientSeed  string
+	undoDepth   *int
+	ttl         *time.Duration
+	tags        []string
 }
 
 // DeckCreationOption is the interface implemented to allow options while creating a new Deck.
@@ -36,6 +152,55 @@ type DeckCreationOption interface {
 	apply(*deckCreationOptions)
 }
 
+type visibilityOption domain.Visibility
+
+func (c visibilityOption) apply(o *deckCreationOptions) {
+	v := domain.Visibility(c)
+	o.visibility = &v
+}
+
+// WithVisibility allows to set the visibility of the remaining cards of the new deck, face up by default.
+func WithVisibility(v domain.Visibility) DeckCreationOption {
+	return visibilityOption(v)
+}
+
+type ttlOption time.Duration
+
+func (c ttlOption) apply(o *deckCreationOptions) {
+	ttl := time.Duration(c)
+	o.ttl = &ttl
+}
+
+// WithTTL allows to make the new deck expire once the given time to live passes, instead of the default time to live
+// of the service.
+func WithTTL(ttl time.Duration) DeckCreationOption {
+	return ttlOption(ttl)
+}
+
+type undoDepthOption int
+
+func (c undoDepthOption) apply(o *deckCreationOptions) {
+	depth := int(c)
+	o.undoDepth = &depth
+}
+
+// WithUndoDepth allows to set the maximum number of operations that can be undone on the new deck, 0 to disable
+// undo. By default it is domain.DefaultUndoDepth, or 0 for provably fair decks.
+func WithUndoDepth(depth int) DeckCreationOption {
+	return undoDepthOption(depth)
+}
+
+type tagsOption []string
+
+func (c tagsOption) apply(o *deckCreationOptions) {
+	o.tags = c
+}
+
+// WithTags allows to tag the new deck, so it can be found when listing the decks.
+func WithTags(tags ...string) DeckCreationOption {
+	return tagsOption(tags)
+}
+
 type shuffledOption bool
 
 func (c shuffledOption) apply(o *deckCreationOptions) {
@@ -47,6 +212,100 @@ func Shuffled(s bool) DeckCreationOption {
 	return shuffledOption(s)
 }
 
+// compositionToConfigure returns the composition being configured, starting from a complete deck if none was.
+func (o *deckCreationOptions) compositionToConfigure() *domain.Composition {
+	if o.composition == nil {
+		o.composition = &domain.Composition{}
+	}
+
+	return o.composition
+}
+
+type decksOption int
+
+func (c decksOption) apply(o *deckCreationOptions) {
+	o.compositionToConfigure().Decks = int(c)
+}
+
+// WithDecks allows to build the new deck as a multi-deck shoe combining the given number of decks.
+func WithDecks(n int) DeckCreationOption {
+	return decksOption(n)
+}
+
+type penetrationOption float64
+
+func (c penetrationOption) apply(o *deckCreationOptions) {
+	p := float64(c)
+	o.penetration = &p
+}
+
+// WithPenetration allows to place a cut card after the given fraction of the new deck, between 0 and 1,
+// so the deck reports when it should be reshuffled.
+func WithPenetration(p float64) DeckCreationOption {
+	return penetrationOption(p)
+}
+
+type jokersOption int
+
+func (c jokersOption) apply(o *deckCreationOptions) {
+	o.compositionToConfigure().Jokers = int(c)
+}
+
+// WithJokers allows to add the given number of jokers to the new deck.
+func WithJokers(n int) DeckCreationOption {
+	return jokersOption(n)
+}
+
+type suitsOption []domain.Suit
+
+func (c suitsOption) apply(o *deckCreationOptions) {
+	o.compositionToConfigure().Suits = c
+}
+
+// WithSuits allows to restrict the new deck to the given suits.
+func WithSuits(suits ...domain.Suit) DeckCreationOption {
+	return suitsOption(suits)
+}
+
+type rankRangeOption struct {
+	lowest  domain.Rank
+	highest domain.Rank
+}
+
+func (c rankRangeOption) apply(o *deckCreationOptions) {
+	composition := o.compositionToConfigure()
+	composition.LowestRank = c.lowest
+	composition.HighestRank = c.highest
+}
+
+// WithRankRange allows to restrict the new deck to an inclusive range of ranks, such as 7 through Ace.
+// See domain.Composition for how aces are ranked.
+func WithRankRange(lowest, highest domain.Rank) DeckCreationOption {
+	return rankRangeOption{lowest: lowest, highest: highest}
+}
+
+type excludedRanksOption []domain.Rank
+
+func (c excludedRanksOption) apply(o *deckCreationOptions) {
+	o.compositionToConfigure().ExcludedRanks = c
+}
+
+// WithoutRanks allows to remove the given ranks from the new deck.
+func WithoutRanks(ranks ...domain.Rank) DeckCreationOption {
+	return excludedRanksOption(ranks)
+}
+
+type shuffleStrategyOption domain.ShuffleStrategy
+
+func (c shuffleStrategyOption) apply(o *deckCreationOptions) {
+	o.strategy = domain.ShuffleStrategy(c)
+}
+
+// WithShuffleStrategy allows to specify the strategy used to shuffle the new deck.
+func WithShuffleStrategy(s domain.ShuffleStrategy) DeckCreationOption {
+	return shuffleStrategyOption(s)
+}
+
 type cardsOption struct {
 	cards []domain.Card
 }
@@ -55,95 +314,576 @@ func (c cardsOption) apply(o *deckCreationOptions) {
 	o.cards = c.cards
 }
 
-// WithCards allows to specify the cards in the new deck being created.
+// WithCards allows to specify the cards in the new deck being created. Can't be combined with the composition options.
 func WithCards(cards []domain.Card) DeckCreationOption {
 	return cardsOption{cards: cards}
 }
 
+type seedOption int64
+
+func (c seedOption) apply(o *deckCreationOptions) {
+	seed := int64(c)
+	o.seed = &seed
+}
+
+// WithSeed allows to specify the seed used to shuffle the new deck, so its order can be reproduced.
+func WithSeed(seed int64) DeckCreationOption {
+	return seedOption(seed)
+}
+
+type provablyFairOption string
+
+func (c provablyFairOption) apply(o *deckCreationOptions) {
+	o.fair = true
+	o.clientSeed = string(c)
+}
+
+// ProvablyFair option to make the new deck provably fair, shuffling it with a random server seed combined
+// with the client seed given. Only the commitment of the server seed is published until the deck is revealed.
+func ProvablyFair(clientSeed string) DeckCreationOption {
+	return provablyFairOption(clientSeed)
+}
+
 // CreateDeckOutput is the result of creating a new deck.
 type CreateDeckOutput struct {
-	DeckID    string `json:"deck_id"`
-	Shuffled  bool   `json:"shuffled"`
-	Remaining int    `json:"remaining"`
+	DeckID          string                 `json:"deck_id"`
+	Version         int64                  `json:"version"`
+	Shuffled        bool                   `json:"shuffled"`
+	Remaining       int                    `json:"remaining"`
+	ShuffleStrategy domain.ShuffleStrategy `json:"shuffle_strategy,omitempty"`
+	Seed            *int64                 `json:"seed,omitempty"`
+	Commitment      string                 `json:"commitment,omitempty"`
+	ClientSeed      string                 `json:"client_seed,omitempty"`
+	Owner           string                 `json:"owner,omitempty"`
+	Tags            []string               `json:"tags,omitempty"`
+	ExpiresAt       *time.Time             `json:"expires_at,omitempty"`
 }
 
-func createDeckOutputFromDeck(d *domain.Deck) CreateDeckOutput {
-	return CreateDeckOutput{
-		DeckID:    d.UUID,
-		Shuffled:  d.Shuffled,
-		Remaining: len(d.Cards),
+// createDeckOutputFromDeck returns the new deck as viewed by the player, without its seed if the player can't see
+// its cards.
+func createDeckOutputFromDeck(d *domain.Deck, player string) CreateDeckOutput {
+	out := CreateDeckOutput{
+		DeckID:          d.UUID,
+		Version:         d.Version,
+		Shuffled:        d.Shuffled,
+		Remaining:       len(d.Cards),
+		ShuffleStrategy: d.ShuffleStrategy,
+		Owner:           d.Owner,
+		Tags:            d.Tags,
+		ExpiresAt:       d.ExpiresAt,
 	}
+
+	switch {
+	case d.Fairness != nil:
+		// The seed would give away the order of the deck, only the commitment is published.
+		out.Commitment = d.Fairness.Commitment
+		out.ClientSeed = d.Fairness.ClientSeed
+	case d.ShuffleStrategy == domain.SeededShuffle && d.EffectiveVisibility().VisibleTo(player):
+		seed := d.Seed
+		out.Seed = &seed
+	}
+
+	return out
 }
 
 // CreateDeck creates a new deck. By default creates a shuffled complete deck, unless the options say otherwise.
-// Returns an error if the new deck couldn't be saved.
+// Shuffled decks take their seed from the seed source of the service if no seed is given.
+// Returns an error if the options can't be combined or if the new deck couldn't be saved.
 func (s *DeckService) CreateDeck(ctx context.Context, opts ...DeckCreationOption) (CreateDeckOutput, error) {
 	options := deckCreationOptions{
 		shuffled: true,
-		cards:    domain.CompleteDeckCards(),
+		strategy: domain.SeededShuffle,
 	}
 
 	for _, o := range opts {
 		o.apply(&options)
 	}
 
-	d := domain.NewDeck(options.shuffled, options.cards)
+	if err := options.resolveCards(); err != nil {
+		return CreateDeckOutput{}, err
+	}
+
+	deckOpts, err := s.domainDeckOptions(options)
+
+	if err != nil {
+		return CreateDeckOutput{}, err
+	}
+
+	d := domain.NewDeck(options.shuffled, options.cards, deckOpts...)
+	d.Owner = PlayerFromContext(ctx)
+	d.CreatedAt = s.now().UTC()
+	d.UpdatedAt = d.CreatedAt
+
+	if err := d.SetTags(options.tags); err != nil {
+		return CreateDeckOutput{}, err
+	}
+
+	if ttl := s.ttlFor(options); ttl != nil {
+		if err := d.SetTTL(*ttl); err != nil {
+			return CreateDeckOutput{}, err
+		}
+	}
+
+	if options.penetration != nil {
+		if err := d.PlaceCutCard(*options.penetration); err != nil {
+			return CreateDeckOutput{}, err
+		}
+	}
+
+	if options.visibility != nil {
+		if err := d.SetVisibility(*options.visibility); err != nil {
+			return CreateDeckOutput{}, err
+		}
+	}
+
+	undoDepth, err := options.resolveUndoDepth()
+
+	if err != nil {
+		return CreateDeckOutput{}, err
+	}
+
+	if err := d.SetUndoDepth(undoDepth); err != nil {
+		return CreateDeckOutput{}, err
+	}
+
+	s.record(ctx, d, domain.CreatedEvent)
 
 	if err := s.deckRepository.Save(ctx, d); err != nil {
 		return CreateDeckOutput{}, fmt.Errorf("saving the deck failed: %w", err)
 	}
 
-	return createDeckOutputFromDeck(d), nil
+	return createDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// resolveCards sets the cards of the new deck from its composition when no cards were given explicitly.
+func (o *deckCreationOptions) resolveCards() error {
+	if o.cards != nil {
+		if o.composition != nil {
+			return fmt.Errorf("%w: the cards can't be combined with a composition", ErrInvalidOption)
+		}
+
+		return nil
+	}
+
+	if o.composition == nil {
+		o.cards = domain.CompleteDeckCards()
+		return nil
+	}
+
+	cards, err := o.composition.Cards()
+
+	if err != nil {
+		return err
+	}
+
+	o.cards = cards
+
+	return nil
+}
+
+// ttlFor returns the time to live of the new deck, nil if it never expires.
+func (s *DeckService) ttlFor(options deckCreationOptions) *time.Duration {
+	if options.ttl != nil {
+		return options.ttl
+	}
+
+	if s.defaultTTL > 0 {
+		return &s.defaultTTL
+	}
+
+	return nil
+}
+
+// resolveUndoDepth returns the undo depth of the new deck. Provably fair decks can't be undone, since undoing
+// draws would let the players see cards again and choose the outcome.
+func (o *deckCreationOptions) resolveUndoDepth() (int, error) {
+	if o.undoDepth == nil {
+		if o.fair {
+			return 0, nil
+		}
+
+		return domain.DefaultUndoDepth, nil
+	}
+
+	if o.fair && *o.undoDepth != 0 {
+		return 0, fmt.Errorf("%w: provably fair decks can't be undone", ErrInvalidOption)
+	}
+
+	return *o.undoDepth, nil
+}
+
+// checkReorderable returns an error if the deck is provably fair. Reordering it after its creation, with seeds that
+// weren't committed, would reveal a server seed proving an order the deck no longer has.
+func checkReorderable(d *domain.Deck) error {
+	if d.Fairness != nil {
+		return domain.ErrFairDeckReorder
+	}
+
+	return nil
+}
+
+// domainDeckOptions translates the creation options into the options of the new domain deck.
+func (s *DeckService) domainDeckOptions(options deckCreationOptions) ([]domain.DeckOption, error) {
+	if options.strategy == domain.SecureShuffle {
+		if options.seed != nil || options.fair {
+			return nil, fmt.Errorf("%w: the secure shuffle can't be reproduced from seeds", ErrInvalidOption)
+		}
+
+		return []domain.DeckOption{domain.WithShuffleStrategy(domain.SecureShuffle)}, nil
+	}
+
+	if options.fair {
+		if !options.shuffled {
+			return nil, fmt.Errorf("%w: provably fair decks must be shuffled", ErrInvalidOption)
+		}
+
+		if options.seed != nil {
+			return nil, fmt.Errorf("%w: provably fair decks can't be given a seed", ErrInvalidOption)
+		}
+
+		f, err := domain.NewFairness(options.clientSeed)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return []domain.DeckOption{domain.WithFairness(f)}, nil
+	}
+
+	seed := options.seed
+
+	if seed == nil && options.shuffled {
+		next := s.nextSeed()
+		seed = &next
+	}
+
+	if seed == nil {
+		return nil, nil
+	}
+
+	return []domain.DeckOption{domain.WithSeed(*seed)}, nil
 }
 
 // OpenDeckOutput is the result of opening a deck.
 type OpenDeckOutput struct {
-	DeckID    string        `json:"deck_id"`
-	Shuffled  bool          `json:"shuffled"`
-	Remaining int           `json:"remaining"`
-	Cards     []domain.Card `json:"cards"`
+	DeckID          string             `json:"deck_id"`
+	Version         int64              `json:"version"`
+	Shuffled        bool               `json:"shuffled"`
+	Remaining       int                `json:"remaining"`
+	Cards           []domain.Card      `json:"cards"`
+	Hidden          bool               `json:"hidden,omitempty"`
+	ReshuffleNeeded bool               `json:"reshuffle_needed,omitempty"`
+	Piles           []PileSummary      `json:"piles,omitempty"`
+	Hands           []HandSummary      `json:"hands,omitempty"`
+	Operations      []domain.Operation `json:"operations,omitempty"`
+	Owner           string             `json:"owner,omitempty"`
+	Tags            []string           `json:"tags,omitempty"`
+	ExpiresAt       *time.Time         `json:"expires_at,omitempty"`
+	ClosedAt        *time.Time         `json:"closed_at,omitempty"`
 }
 
-func openDeckOutputFromDeck(d *domain.Deck) OpenDeckOutput {
+// openDeckOutputFromDeck returns the deck as viewed by the player, hiding the cards the player can't see along with
+// the seeds of the operations applied to them.
+func openDeckOutputFromDeck(d *domain.Deck, player string) OpenDeckOutput {
+	cards, hidden := viewCards(d.Cards, d.EffectiveVisibility(), player)
+
 	return OpenDeckOutput{
-		DeckID:    d.UUID,
-		Shuffled:  d.Shuffled,
-		Remaining: len(d.Cards),
-		Cards:     d.Cards,
+		DeckID:          d.UUID,
+		Version:         d.Version,
+		Shuffled:        d.Shuffled,
+		Remaining:       len(d.Cards),
+		Cards:           cards,
+		Hidden:          hidden,
+		ReshuffleNeeded: d.NeedsReshuffle(),
+		Piles:           pileSummaries(d),
+		Hands:           handSummaries(d),
+		Operations:      viewOperations(d.Operations, hidden),
+		Owner:           d.Owner,
+		Tags:            d.Tags,
+		ExpiresAt:       d.ExpiresAt,
+		ClosedAt:        d.ClosedAt,
 	}
 }
 
-// OpenDeck opens the deck with the given UUID.
+// OpenDeck opens the deck with the given UUID, as viewed by the player in the context.
 // Returns an error if there is no deck with the given UUID.
 func (s *DeckService) OpenDeck(ctx context.Context, uuid string) (OpenDeckOutput, error) {
-	d, err := s.deckRepository.Get(ctx, uuid)
+	d, err := s.getDeck(ctx, uuid)
 
 	if err != nil {
-		return OpenDeckOutput{}, fmt.Errorf("getting the deck failed: %w", err)
+		return OpenDeckOutput{}, err
 	}
 
-	return openDeckOutputFromDeck(d), nil
+	return openDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
 }
 
 // DrawCardsOutput is the result of drawing cards from a deck.
 type DrawCardsOutput struct {
-	Cards []domain.Card `json:"cards"`
+	Version         int64         `json:"version"`
+	Cards           []domain.Card `json:"cards"`
+	ReshuffleNeeded bool          `json:"reshuffle_needed,omitempty"`
+}
+
+type drawOptions struct {
+	position domain.Position
+	cards    []domain.Card
+}
+
+// DrawOption is the interface implemented to allow options while drawing cards.
+type DrawOption interface {
+	apply(*drawOptions)
+}
+
+type drawFromOption domain.Position
+
+func (c drawFromOption) apply(o *drawOptions) {
+	o.position = domain.Position(c)
+}
+
+// DrawFrom allows to draw the cards from the given position of the deck instead of its top.
+func DrawFrom(p domain.Position) DrawOption {
+	return drawFromOption(p)
+}
+
+type drawSpecificOption struct {
+	cards []domain.Card
+}
+
+func (c drawSpecificOption) apply(o *drawOptions) {
+	o.cards = c.cards
+}
+
+// DrawSpecific allows to draw the given cards from wherever they are in the deck. The amount and the position
+// are ignored.
+func DrawSpecific(cards []domain.Card) DrawOption {
+	return drawSpecificOption{cards: cards}
+}
+
+// DrawCards draws the given amount of cards from the deck with the given UUID. By default the cards are drawn
+// from the top of the deck, unless the options say otherwise.
+// Returns an error if there is no deck with the given UUID, if the position is unknown, if any of the specific
+// cards isn't in the deck or if saving the modified deck failed.
+func (s *DeckService) DrawCards(ctx context.Context, uuid string, amount int, opts ...DrawOption) (DrawCardsOutput, error) {
+	options := drawOptions{
+		position: domain.PositionTop,
+	}
+
+	for _, o := range opts {
+		o.apply(&options)
+	}
+
+	var drawnCards []domain.Card
+
+	d, err := s.updateDeck(ctx, uuid, domain.DrawnEvent, func(d *domain.Deck) (err error) {
+		if options.cards != nil {
+			drawnCards, err = d.DrawCards(options.cards)
+			return err
+		}
+
+		drawnCards, err = d.DrawFrom(options.position, amount, s.shufflerFor(d))
+
+		return err
+	})
+
+	if err != nil {
+		return DrawCardsOutput{}, err
+	}
+
+	return DrawCardsOutput{Version: d.Version, Cards: drawnCards, ReshuffleNeeded: d.NeedsReshuffle()}, nil
 }
 
-// DrawCards draws the given amount of cards from the deck with the given UUID.
-// Returns an error if there is no deck with the given UUID or if saving the modified deck failed.
-func (s *DeckService) DrawCards(ctx context.Context, uuid string, amount int) (DrawCardsOutput, error) {
+// updateDeck applies the update to the deck with the given UUID, records it in the history of the deck as an event
+// of the given type and saves the deck, while holding the lock of the deck. If the update fails the deck isn't saved.
+// Returns the updated deck, or an error if there is no deck with the given UUID, if it isn't at the version expected
+// in the context, if it is closed, if the update failed or if saving the modified deck failed.
+func (s *DeckService) updateDeck(
+	ctx context.Context, uuid string, eventType domain.EventType, update func(d *domain.Deck) error,
+) (*domain.Deck, error) {
+	return s.modifyDeck(ctx, uuid, func(d *domain.Deck) error {
+		if err := update(d); err != nil {
+			return err
+		}
+
+		s.record(ctx, d, eventType)
+
+		return nil
+	})
+}
+
+// getDeck gets the deck with the given UUID. Returns an error if there is no deck with the given UUID or if it expired.
+func (s *DeckService) getDeck(ctx context.Context, uuid string) (*domain.Deck, error) {
 	d, err := s.deckRepository.Get(ctx, uuid)
 
 	if err != nil {
-		return DrawCardsOutput{}, fmt.Errorf("getting the deck failed: %w", err)
+		return nil, fmt.Errorf("getting the deck failed: %w", err)
+	}
+
+	if d.Expired(s.now()) {
+		return nil, domain.ErrDeckExpired
+	}
+
+	return d, nil
+}
+
+// modifyDeck is like updateDeck, but leaves recording the event to the update.
+func (s *DeckService) modifyDeck(ctx context.Context, uuid string, update func(d *domain.Deck) error) (*domain.Deck, error) {
+	unlock := s.locks.lock(uuid)
+	defer unlock()
+
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return nil, err
 	}
 
-	drawnCards := d.Draw(amount)
+	if err := checkExpectedVersion(ctx, d); err != nil {
+		return nil, err
+	}
+
+	if d.Closed() {
+		return nil, domain.ErrDeckClosed
+	}
+
+	if err := update(d); err != nil {
+		return nil, err
+	}
+
+	d.UpdatedAt = s.now().UTC()
 
 	if err := s.deckRepository.Save(ctx, d); err != nil {
-		return DrawCardsOutput{}, fmt.Errorf("saving the deck failed: %w", err)
+		return nil, fmt.Errorf("saving the deck failed: %w", err)
+	}
+
+	return d, nil
+}
+
+// RevealDeckOutput is the result of revealing the secrets of a provably fair deck.
+type RevealDeckOutput struct {
+	DeckID     string `json:"deck_id"`
+	ServerSeed string `json:"server_seed"`
+	ClientSeed string `json:"client_seed"`
+	Commitment string `json:"commitment"`
+	Seed       int64  `json:"seed"`
+}
+
+// RevealDeck reveals the server seed of the provably fair deck with the given UUID, so its order can be verified.
+// Returns an error if there is no deck with the given UUID, if the deck isn't provably fair or if the deck
+// can still be drawn from.
+func (s *DeckService) RevealDeck(ctx context.Context, uuid string) (RevealDeckOutput, error) {
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return RevealDeckOutput{}, err
+	}
+
+	if d.Fairness == nil {
+		return RevealDeckOutput{}, domain.ErrDeckNotFair
+	}
+
+	if !d.Revealable() {
+		return RevealDeckOutput{}, domain.ErrDeckNotRevealable
+	}
+
+	return RevealDeckOutput{
+		DeckID:     d.UUID,
+		ServerSeed: d.Fairness.ServerSeed,
+		ClientSeed: d.Fairness.ClientSeed,
+		Commitment: d.Fairness.Commitment,
+		Seed:       d.Seed,
+	}, nil
+}
+
+// ReturnCards places the given cards, previously drawn from the deck with the given UUID, back in the deck at the
+// given position. Returns an error if there is no deck with the given UUID, if the position is unknown, if any of
+// the cards wasn't drawn or is already in a pile, if the cards are returned at random positions of a provably fair
+// deck, or if saving the modified deck failed.
+func (s *DeckService) ReturnCards(ctx context.Context, uuid string, cards []domain.Card, position domain.Position) (OpenDeckOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.ReturnedEvent, func(d *domain.Deck) error {
+		if position == domain.PositionRandom {
+			if err := checkReorderable(d); err != nil {
+				return err
+			}
+		}
+
+		return d.Return(cards, position, s.shufflerFor(d))
+	})
+
+	if err != nil {
+		return OpenDeckOutput{}, err
+	}
+
+	return openDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// ReshuffleDeck shuffles the cards remaining in the deck with the given UUID, following the shuffle strategy of the
+// deck. Returns an error if there is no deck with the given UUID, if the deck is provably fair or if saving the
+// modified deck failed.
+func (s *DeckService) ReshuffleDeck(ctx context.Context, uuid string) (OpenDeckOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.ReshuffledEvent, func(d *domain.Deck) error {
+		if err := checkReorderable(d); err != nil {
+			return err
+		}
+
+		d.Reshuffle(s.shufflerFor(d))
+
+		return nil
+	})
+
+	if err != nil {
+		return OpenDeckOutput{}, err
 	}
 
-	return DrawCardsOutput{Cards: drawnCards}, nil
+	return openDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// ResetDeck gathers every card of the deck with the given UUID back into it and reshuffles it, following the shuffle
+// strategy of the deck. Returns an error if there is no deck with the given UUID, if the deck is provably fair or if
+// saving the modified deck failed.
+func (s *DeckService) ResetDeck(ctx context.Context, uuid string) (OpenDeckOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.ResetEvent, func(d *domain.Deck) error {
+		if err := checkReorderable(d); err != nil {
+			return err
+		}
+
+		d.Reset(s.shufflerFor(d))
+
+		return nil
+	})
+
+	if err != nil {
+		return OpenDeckOutput{}, err
+	}
+
+	return openDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// PeekCardsOutput is the result of peeking at cards of a deck.
+type PeekCardsOutput struct {
+	DeckID    string        `json:"deck_id"`
+	Version   int64         `json:"version"`
+	Remaining int           `json:"remaining"`
+	Cards     []domain.Card `json:"cards"`
+	Hidden    bool          `json:"hidden,omitempty"`
+}
+
+// PeekCards returns the given amount of cards from the top or the bottom of the deck with the given UUID, without
+// drawing them. The cards are hidden if the player in the context can't see the remaining cards of the deck.
+// Returns an error if there is no deck with the given UUID, if the amount is negative or if the position isn't the
+// top or the bottom.
+func (s *DeckService) PeekCards(ctx context.Context, uuid string, amount int, position domain.Position) (PeekCardsOutput, error) {
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return PeekCardsOutput{}, err
+	}
+
+	peeked, err := d.Peek(position, amount)
+
+	if err != nil {
+		return PeekCardsOutput{}, err
+	}
+
+	cards, hidden := viewCards(peeked, d.EffectiveVisibility(), PlayerFromContext(ctx))
+
+	return PeekCardsOutput{DeckID: d.UUID, Version: d.Version, Remaining: len(d.Cards), Cards: cards, Hidden: hidden}, nil
 }
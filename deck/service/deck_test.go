@@ -6,8 +6,10 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
 	"github.com/cfagudelo96/toggle-test/deck/service"
 	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
 	"github.com/stretchr/testify/mock"
@@ -15,6 +17,17 @@ import (
 
 //go:generate mockery --name DeckRepository
 
+// fixedSeedSource is a seed source that always returns the same seed.
+type fixedSeedSource int64
+
+func (s fixedSeedSource) Int63() int64 {
+	return int64(s)
+}
+
+func seedPtr(seed int64) *int64 {
+	return &seed
+}
+
 func TestDeckService_CreateDeck(t *testing.T) {
 	ctx := context.Background()
 	deckRepositoryMock := &mocks.DeckRepository{}
@@ -49,27 +62,108 @@ func TestDeckService_CreateDeck(t *testing.T) {
 			name:           "works correctly with the cards option",
 			deckRepository: deckRepositoryMock,
 			opts: []service.DeckCreationOption{
-				service.WithCards([]domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}}),
+				service.WithCards([]domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}}),
+			},
+			want: service.CreateDeckOutput{
+				DeckID:          "some-deck-id",
+				Shuffled:        true,
+				Remaining:       2,
+				ShuffleStrategy: domain.SeededShuffle,
+				Seed:            seedPtr(42),
+			},
+		},
+		{
+			name:           "works correctly with the seed option",
+			deckRepository: deckRepositoryMock,
+			opts:           []service.DeckCreationOption{service.WithSeed(7)},
+			want: service.CreateDeckOutput{
+				DeckID:          "some-deck-id",
+				Shuffled:        true,
+				Remaining:       52,
+				ShuffleStrategy: domain.SeededShuffle,
+				Seed:            seedPtr(7),
+			},
+		},
+		{
+			name:           "works correctly with the composition options",
+			deckRepository: deckRepositoryMock,
+			opts: []service.DeckCreationOption{
+				service.Shuffled(false),
+				service.WithJokers(2),
+				service.WithRankRange(domain.Nine, domain.Ace),
+				service.WithSuits(domain.Hearts, domain.Spades),
+				service.WithoutRanks(domain.Ten),
 			},
 			want: service.CreateDeckOutput{
 				DeckID:    "some-deck-id",
-				Shuffled:  true,
-				Remaining: 2,
+				Shuffled:  false,
+				Remaining: 12,
 			},
 		},
 		{
-			name:           "works correctly without options",
+			name:           "works correctly with a multi-deck shoe",
 			deckRepository: deckRepositoryMock,
+			opts: []service.DeckCreationOption{
+				service.Shuffled(false),
+				service.WithDecks(6),
+				service.WithPenetration(0.75),
+			},
 			want: service.CreateDeckOutput{
 				DeckID:    "some-deck-id",
-				Shuffled:  true,
-				Remaining: 52,
+				Shuffled:  false,
+				Remaining: 312,
+			},
+		},
+		{
+			name:           "returns an error with an invalid penetration",
+			deckRepository: deckRepositoryMock,
+			opts:           []service.DeckCreationOption{service.WithPenetration(1.5)},
+			wantErr:        true,
+		},
+		{
+			name:           "returns an error with the cards and composition options",
+			deckRepository: deckRepositoryMock,
+			opts: []service.DeckCreationOption{
+				service.WithCards([]domain.Card{{Rank: domain.Four, Suit: domain.Hearts}}),
+				service.WithJokers(1),
+			},
+			wantErr: true,
+		},
+		{
+			name:           "works correctly with the secure shuffle strategy",
+			deckRepository: deckRepositoryMock,
+			opts:           []service.DeckCreationOption{service.WithShuffleStrategy(domain.SecureShuffle)},
+			want: service.CreateDeckOutput{
+				DeckID:          "some-deck-id",
+				Shuffled:        true,
+				Remaining:       52,
+				ShuffleStrategy: domain.SecureShuffle,
+			},
+		},
+		{
+			name:           "returns an error with the secure shuffle strategy and a seed",
+			deckRepository: deckRepositoryMock,
+			opts: []service.DeckCreationOption{
+				service.WithShuffleStrategy(domain.SecureShuffle),
+				service.WithSeed(7),
+			},
+			wantErr: true,
+		},
+		{
+			name:           "works correctly without options",
+			deckRepository: deckRepositoryMock,
+			want: service.CreateDeckOutput{
+				DeckID:          "some-deck-id",
+				Shuffled:        true,
+				Remaining:       52,
+				ShuffleStrategy: domain.SeededShuffle,
+				Seed:            seedPtr(42),
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s := service.NewDeckService(tt.deckRepository)
+			s := service.NewDeckService(tt.deckRepository, service.WithSeedSource(fixedSeedSource(42)))
 			got, err := s.CreateDeck(ctx, tt.opts...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeckService.CreateDeck() error = %v, wantErr %v", err, tt.wantErr)
@@ -108,7 +202,7 @@ func TestDeckService_OpenDeck(t *testing.T) {
 				m.On("Get", ctx, uuid).Return(&domain.Deck{
 					UUID:     uuid,
 					Shuffled: true,
-					Cards:    []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}},
+					Cards:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
 				}, nil)
 				return m
 			}(),
@@ -116,7 +210,7 @@ func TestDeckService_OpenDeck(t *testing.T) {
 				DeckID:    uuid,
 				Shuffled:  true,
 				Remaining: 2,
-				Cards:     []domain.Card{{Value: "4", Suit: "HEARTS", Code: "4H"}, {Value: "5", Suit: "SPADES", Code: "5S"}},
+				Cards:     []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
 			},
 		},
 	}
@@ -134,3 +228,281 @@ func TestDeckService_OpenDeck(t *testing.T) {
 		})
 	}
 }
+
+func TestDeckService_CreateDeck_ProvablyFair(t *testing.T) {
+	ctx := context.Background()
+	m := &mocks.DeckRepository{}
+	m.On("Save", ctx, mock.Anything).Return(nil)
+	s := service.NewDeckService(m)
+
+	t.Run("publishes the commitment instead of the seed", func(t *testing.T) {
+		got, err := s.CreateDeck(ctx, service.ProvablyFair("client-seed"))
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		if got.Seed != nil {
+			t.Errorf("DeckService.CreateDeck() seed = %d, want no seed", *got.Seed)
+		}
+		if got.Commitment == "" || got.ClientSeed != "client-seed" {
+			t.Errorf("DeckService.CreateDeck() = %v, want a commitment and the client seed", got)
+		}
+	})
+	t.Run("returns an error if a seed is given", func(t *testing.T) {
+		_, err := s.CreateDeck(ctx, service.ProvablyFair("client-seed"), service.WithSeed(1))
+		if !errors.Is(err, service.ErrInvalidOption) {
+			t.Errorf("DeckService.CreateDeck() error = %v, want %v", err, service.ErrInvalidOption)
+		}
+	})
+}
+
+func TestDeckService_ProvablyFair_Reorder(t *testing.T) {
+	ctx := context.Background()
+	index := 10
+	tests := []struct {
+		name    string
+		reorder func(s *service.DeckService, uuid string, drawn []domain.Card) error
+		wantErr error
+	}{
+		{
+			name: "rejects reshuffling",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.ReshuffleDeck(ctx, uuid)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects resetting",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.ResetDeck(ctx, uuid)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects returning cards at random positions",
+			reorder: func(s *service.DeckService, uuid string, drawn []domain.Card) error {
+				_, err := s.ReturnCards(ctx, uuid, drawn, domain.PositionRandom)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects cutting at an index",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.CutDeck(ctx, uuid, &index)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects cutting at random",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.CutDeck(ctx, uuid, nil)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects riffle shuffling",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.RiffleShuffle(ctx, uuid, 1)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "rejects overhand shuffling",
+			reorder: func(s *service.DeckService, uuid string, _ []domain.Card) error {
+				_, err := s.OverhandShuffle(ctx, uuid, 1)
+				return err
+			},
+			wantErr: domain.ErrFairDeckReorder,
+		},
+		{
+			name: "allows returning cards at the top",
+			reorder: func(s *service.DeckService, uuid string, drawn []domain.Card) error {
+				_, err := s.ReturnCards(ctx, uuid, drawn, domain.PositionTop)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := service.NewDeckService(repository.NewInMemoryDeckRepository())
+			created, err := s.CreateDeck(ctx, service.ProvablyFair("client-seed"))
+			if err != nil {
+				t.Fatalf("DeckService.CreateDeck() error = %v", err)
+			}
+			drawn, err := s.DrawCards(ctx, created.DeckID, 2)
+			if err != nil {
+				t.Fatalf("DeckService.DrawCards() error = %v", err)
+			}
+			before, err := s.OpenDeck(ctx, created.DeckID)
+			if err != nil {
+				t.Fatalf("DeckService.OpenDeck() error = %v", err)
+			}
+
+			err = tt.reorder(s, created.DeckID, drawn.Cards)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reordering the deck error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				return
+			}
+			after, err := s.OpenDeck(ctx, created.DeckID)
+			if err != nil {
+				t.Fatalf("DeckService.OpenDeck() error = %v", err)
+			}
+			if !reflect.DeepEqual(after, before) {
+				t.Errorf("DeckService.OpenDeck() = %v, want the deck unchanged %v", after, before)
+			}
+		})
+	}
+}
+
+func TestDeckService_RevealDeck(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	fairness := &domain.Fairness{ServerSeed: "server-seed", ClientSeed: "client-seed", Commitment: domain.Commit("server-seed")}
+	closedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		deck    *domain.Deck
+		want    service.RevealDeckOutput
+		wantErr error
+	}{
+		{
+			name:    "returns an error if the deck isn't provably fair",
+			deck:    &domain.Deck{UUID: uuid, Shuffled: true},
+			wantErr: domain.ErrDeckNotFair,
+		},
+		{
+			name: "returns an error if the deck still has cards",
+			deck: &domain.Deck{
+				UUID:     uuid,
+				Shuffled: true,
+				Cards:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}},
+				Fairness: fairness,
+			},
+			wantErr: domain.ErrDeckNotRevealable,
+		},
+		{
+			name: "reveals the seeds of an exhausted deck",
+			deck: &domain.Deck{UUID: uuid, Shuffled: true, Seed: 3, Fairness: fairness},
+			want: service.RevealDeckOutput{
+				DeckID:     uuid,
+				ServerSeed: "server-seed",
+				ClientSeed: "client-seed",
+				Commitment: domain.Commit("server-seed"),
+				Seed:       3,
+			},
+		},
+		{
+			name: "reveals the seeds of a closed deck with cards left",
+			deck: &domain.Deck{
+				UUID:     uuid,
+				Shuffled: true,
+				Seed:     3,
+				Cards:    []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}},
+				Fairness: fairness,
+				ClosedAt: &closedAt,
+			},
+			want: service.RevealDeckOutput{
+				DeckID:     uuid,
+				ServerSeed: "server-seed",
+				ClientSeed: "client-seed",
+				Commitment: domain.Commit("server-seed"),
+				Seed:       3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", ctx, uuid).Return(tt.deck, nil)
+			got, err := service.NewDeckService(m).RevealDeck(ctx, uuid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.RevealDeck() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeckService.RevealDeck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeckService_DrawCards(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	fourHearts := domain.Card{Rank: domain.Four, Suit: domain.Hearts}
+	fiveSpades := domain.Card{Rank: domain.Five, Suit: domain.Spades}
+	tests := []struct {
+		name    string
+		amount  int
+		opts    []service.DrawOption
+		want    service.DrawCardsOutput
+		wantErr error
+	}{
+		{
+			name:   "draws from the top by default",
+			amount: 1,
+			want:   service.DrawCardsOutput{Cards: []domain.Card{fourHearts}},
+		},
+		{
+			name:   "draws from the bottom",
+			amount: 1,
+			opts:   []service.DrawOption{service.DrawFrom(domain.PositionBottom)},
+			want:   service.DrawCardsOutput{Cards: []domain.Card{fiveSpades}},
+		},
+		{
+			name: "draws specific cards",
+			opts: []service.DrawOption{service.DrawSpecific([]domain.Card{fiveSpades})},
+			want: service.DrawCardsOutput{Cards: []domain.Card{fiveSpades}},
+		},
+		{
+			name:    "returns an error if a specific card isn't in the deck",
+			opts:    []service.DrawOption{service.DrawSpecific([]domain.Card{{Rank: domain.Ace, Suit: domain.Spades}})},
+			wantErr: domain.ErrCardsNotInDeck,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", ctx, uuid).Return(&domain.Deck{UUID: uuid, Cards: []domain.Card{fourHearts, fiveSpades}}, nil)
+			m.On("Save", ctx, mock.Anything).Return(nil)
+			got, err := service.NewDeckService(m).DrawCards(ctx, uuid, tt.amount, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.DrawCards() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeckService.DrawCards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeckService_PeekCards(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	m := &mocks.DeckRepository{}
+	m.On("Get", ctx, uuid).Return(&domain.Deck{
+		UUID:  uuid,
+		Cards: []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+	}, nil)
+
+	got, err := service.NewDeckService(m).PeekCards(ctx, uuid, 1, domain.PositionBottom)
+	want := service.PeekCardsOutput{DeckID: uuid, Remaining: 2, Cards: []domain.Card{{Rank: domain.Five, Suit: domain.Spades}}}
+	if err != nil {
+		t.Fatalf("DeckService.PeekCards() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeckService.PeekCards() = %v, want %v", got, want)
+	}
+	m.AssertNotCalled(t, "Save", ctx, mock.Anything)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// HandSummary summarizes the hand of a player in a deck.
+type HandSummary struct {
+	Player string `json:"player"`
+	Cards  int    `json:"cards"`
+}
+
+func handSummaries(d *domain.Deck) []HandSummary {
+	if len(d.Hands) == 0 {
+		return nil
+	}
+
+	summaries := make([]HandSummary, len(d.Hands))
+
+	for i, h := range d.Hands {
+		summaries[i] = HandSummary{Player: h.Player, Cards: len(h.Cards)}
+	}
+
+	return summaries
+}
+
+// HandView is a hand as viewed by a player, without its cards if the player can't see them.
+type HandView struct {
+	Player    string        `json:"player"`
+	Remaining int           `json:"remaining"`
+	Cards     []domain.Card `json:"cards"`
+	Hidden    bool          `json:"hidden,omitempty"`
+}
+
+func viewHand(h *domain.Hand, player string) HandView {
+	cards, hidden := viewCards(h.Cards, h.EffectiveVisibility(), player)
+
+	return HandView{
+		Player:    h.Player,
+		Remaining: len(h.Cards),
+		Cards:     cards,
+		Hidden:    hidden,
+	}
+}
+
+// HandOutput is the result of listing the hand of a player in a deck.
+type HandOutput struct {
+	DeckID  string `json:"deck_id"`
+	Version int64  `json:"version"`
+	HandView
+}
+
+// ListHand lists the cards of the hand of the player in the deck with the given UUID, as viewed by the player in
+// the context. Returns an error if there is no deck with the given UUID or if the player doesn't have a hand in it.
+func (s *DeckService) ListHand(ctx context.Context, uuid, player string) (HandOutput, error) {
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return HandOutput{}, err
+	}
+
+	h, err := d.Hand(player)
+
+	if err != nil {
+		return HandOutput{}, err
+	}
+
+	return HandOutput{DeckID: d.UUID, Version: d.Version, HandView: viewHand(h, PlayerFromContext(ctx))}, nil
+}
+
+// DealCardsOutput is the result of dealing cards from a deck.
+type DealCardsOutput struct {
+	DeckID    string     `json:"deck_id"`
+	Version   int64      `json:"version"`
+	Remaining int        `json:"remaining"`
+	Hands     []HandView `json:"hands"`
+}
+
+// DealCards deals the given amount of cards to each player from the top of the deck with the given UUID, one card
+// at a time in rotation, and returns the hands of the players in the order given, as viewed by the player in the
+// context. The deck is only saved once every card is dealt, so a failure never leaves it half dealt.
+// Returns an error if there is no deck with the given UUID, if the players or the amount are invalid, if the deck
+// doesn't have enough cards or if saving the modified deck failed.
+func (s *DeckService) DealCards(ctx context.Context, uuid string, players []string, cardsPerPlayer int) (DealCardsOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.DealtEvent, func(d *domain.Deck) error {
+		return d.Deal(players, cardsPerPlayer)
+	})
+
+	if err != nil {
+		return DealCardsOutput{}, err
+	}
+
+	out := DealCardsOutput{
+		DeckID:    d.UUID,
+		Version:   d.Version,
+		Remaining: len(d.Cards),
+		Hands:     make([]HandView, len(players)),
+	}
+
+	for i, p := range players {
+		h, err := d.Hand(p)
+
+		if err != nil {
+			return DealCardsOutput{}, err
+		}
+
+		out.Hands[i] = viewHand(h, PlayerFromContext(ctx))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+// failingSaveDeckRepository fails every save after the deck is created.
+type failingSaveDeckRepository struct {
+	*repository.InMemoryDeckRepository
+}
+
+func (r failingSaveDeckRepository) Save(ctx context.Context, d *domain.Deck) error {
+	return errors.New("test")
+}
+
+func TestDeckService_DealCards(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+
+	t.Run("returns the hands of the players in the order given as viewed by the player", func(t *testing.T) {
+		m := &mocks.DeckRepository{}
+		m.On("Get", mock.Anything, uuid).Return(&domain.Deck{
+			UUID:  uuid,
+			Cards: domain.CompleteDeckCards(),
+			Hands: []*domain.Hand{{Player: "carol"}},
+		}, nil)
+		m.On("Save", mock.Anything, mock.Anything).Return(nil)
+		got, err := service.NewDeckService(m).DealCards(service.ContextWithPlayer(ctx, "bob"), uuid, []string{"bob", "alice"}, 1)
+		c := domain.CompleteDeckCards()
+		want := service.DealCardsOutput{
+			DeckID:    uuid,
+			Remaining: 50,
+			Hands: []service.HandView{
+				{Player: "bob", Remaining: 1, Cards: []domain.Card{c[0]}},
+				{Player: "alice", Remaining: 1, Cards: []domain.Card{}, Hidden: true},
+			},
+		}
+		if err != nil {
+			t.Fatalf("DeckService.DealCards() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.DealCards() = %v, want %v", got, want)
+		}
+	})
+	t.Run("leaves the deck untouched if saving fails", func(t *testing.T) {
+		memory := repository.NewInMemoryDeckRepository()
+		d := domain.NewDeck(false, domain.CompleteDeckCards())
+		if err := memory.Save(ctx, d); err != nil {
+			t.Fatalf("InMemoryDeckRepository.Save() error = %v", err)
+		}
+		s := service.NewDeckService(failingSaveDeckRepository{memory})
+		if _, err := s.DealCards(ctx, d.UUID, []string{"alice", "bob"}, 5); err == nil {
+			t.Fatal("DeckService.DealCards() error = nil, want the save error")
+		}
+		got, err := memory.Get(ctx, d.UUID)
+		if err != nil {
+			t.Fatalf("InMemoryDeckRepository.Get() error = %v", err)
+		}
+		if len(got.Cards) != 52 || got.Hands != nil {
+			t.Errorf("Deck after a failed deal = %d cards and hands %v, want 52 cards and no hands", len(got.Cards), got.Hands)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of the context carrying the ID of the request being served, which is
+// recorded in the events caused by the request.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the ID of the request being served, empty if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// record records an event of the given type in the history of the deck, with the request and the player
+// in the context.
+func (s *DeckService) record(ctx context.Context, d *domain.Deck, eventType domain.EventType) {
+	d.Record(eventType, s.now().UTC(), RequestIDFromContext(ctx), PlayerFromContext(ctx))
+}
+
+// getHistory gets the deck with the given UUID along with its history. Returns an error if there is no deck with the
+// given UUID, if it expired or if its history couldn't be retrieved.
+func (s *DeckService) getHistory(ctx context.Context, uuid string) (*domain.Deck, domain.History, error) {
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, err := s.deckRepository.History(ctx, uuid)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting the history failed: %w", err)
+	}
+
+	return d, history, nil
+}
+
+// EventOutput describes an event of the history of a deck, without the state of the deck.
+type EventOutput struct {
+	Number    int              `json:"number"`
+	Type      domain.EventType `json:"type"`
+	At        time.Time        `json:"at"`
+	RequestID string           `json:"request_id,omitempty"`
+	Player    string           `json:"player,omitempty"`
+	// RevertedTo is the number of the event whose state the deck was reverted to, only set for undone events.
+	RevertedTo int `json:"reverted_to,omitempty"`
+}
+
+func eventOutput(e domain.Event) EventOutput {
+	return EventOutput{
+		Number:     e.Number,
+		Type:       e.Type,
+		At:         e.At,
+		RequestID:  e.RequestID,
+		Player:     e.Player,
+		RevertedTo: e.RevertedTo,
+	}
+}
+
+// HistoryOutput is the result of listing the history of a deck.
+type HistoryOutput struct {
+	DeckID  string        `json:"deck_id"`
+	Version int64         `json:"version"`
+	Events  []EventOutput `json:"events"`
+}
+
+// History lists the events of the deck with the given UUID, in the order they happened.
+// Returns an error if there is no deck with the given UUID.
+func (s *DeckService) History(ctx context.Context, uuid string) (HistoryOutput, error) {
+	d, history, err := s.getHistory(ctx, uuid)
+
+	if err != nil {
+		return HistoryOutput{}, err
+	}
+
+	out := HistoryOutput{
+		DeckID:  d.UUID,
+		Version: d.Version,
+		Events:  make([]EventOutput, len(history)),
+	}
+
+	for i, e := range history {
+		out.Events[i] = eventOutput(e)
+	}
+
+	return out, nil
+}
+
+// StateAtOutput is the result of rebuilding the state of a deck right after an event.
+type StateAtOutput struct {
+	Event EventOutput    `json:"event"`
+	Deck  OpenDeckOutput `json:"deck"`
+}
+
+// StateAt rebuilds the state of the deck with the given UUID right after the event with the given number, as viewed
+// by the player in the context. The past state is viewed with the current visibility of the deck, so the cards hidden
+// now aren't given away by its history. Returns an error if there is no deck with the given UUID or if the deck doesn't
+// have the event.
+func (s *DeckService) StateAt(ctx context.Context, uuid string, number int) (StateAtOutput, error) {
+	d, history, err := s.getHistory(ctx, uuid)
+
+	if err != nil {
+		return StateAtOutput{}, err
+	}
+
+	state, err := history.StateAt(number)
+
+	if err != nil {
+		return StateAtOutput{}, err
+	}
+
+	state.CopyVisibility(d)
+
+	return StateAtOutput{
+		Event: eventOutput(history[number-1]),
+		Deck:  openDeckOutputFromDeck(state, PlayerFromContext(ctx)),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+func TestDeckService_History(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(),
+		service.WithSeedSource(fixedSeedSource(42)), service.WithClock(func() time.Time { return at }))
+	ctx := service.ContextWithPlayer(service.ContextWithRequestID(context.Background(), "request-1"), "dealer")
+
+	created, err := s.CreateDeck(ctx, service.Shuffled(false))
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	uuid := created.DeckID
+	drawCtx := service.ContextWithPlayer(service.ContextWithRequestID(context.Background(), "request-2"), "alice")
+	if _, err := s.DrawCards(drawCtx, uuid, 2); err != nil {
+		t.Fatalf("DeckService.DrawCards() error = %v", err)
+	}
+	if _, err := s.DrawCards(context.Background(), uuid, 100, service.DrawFrom("middle")); err == nil {
+		t.Fatal("DeckService.DrawCards() error = nil, want an error")
+	}
+
+	t.Run("lists the events of the successful operations", func(t *testing.T) {
+		got, err := s.History(context.Background(), uuid)
+		want := service.HistoryOutput{
+			DeckID:  uuid,
+			Version: 2,
+			Events: []service.EventOutput{
+				{Number: 1, Type: domain.CreatedEvent, At: at, RequestID: "request-1", Player: "dealer"},
+				{Number: 2, Type: domain.DrawnEvent, At: at, RequestID: "request-2", Player: "alice"},
+			},
+		}
+		if err != nil {
+			t.Fatalf("DeckService.History() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.History() = %v, want %v", got, want)
+		}
+	})
+	t.Run("rebuilds the state of the deck at an event", func(t *testing.T) {
+		got, err := s.StateAt(context.Background(), uuid, 1)
+		if err != nil {
+			t.Fatalf("DeckService.StateAt() error = %v", err)
+		}
+		if got.Event.Type != domain.CreatedEvent || got.Deck.Remaining != 52 {
+			t.Errorf("DeckService.StateAt() = %v, want the complete deck as created", got)
+		}
+	})
+	t.Run("views the state at an event with the current visibility", func(t *testing.T) {
+		if _, err := s.SetDeckVisibility(ctx, uuid, domain.Visibility{Mode: domain.FaceDown}); err != nil {
+			t.Fatalf("DeckService.SetDeckVisibility() error = %v", err)
+		}
+		got, err := s.StateAt(context.Background(), uuid, 1)
+		if err != nil {
+			t.Fatalf("DeckService.StateAt() error = %v", err)
+		}
+		if !got.Deck.Hidden || len(got.Deck.Cards) != 0 || got.Deck.Remaining != 52 {
+			t.Errorf("DeckService.StateAt() = %v, want only the amount of hidden cards", got)
+		}
+	})
+	t.Run("returns an error if the event doesn't exist", func(t *testing.T) {
+		if _, err := s.StateAt(context.Background(), uuid, 4); !errors.Is(err, domain.ErrEventNotFound) {
+			t.Errorf("DeckService.StateAt() error = %v, want %v", err, domain.ErrEventNotFound)
+		}
+	})
+}
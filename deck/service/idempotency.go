@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyKey identifies the requests sharing an idempotency key given by a client. Keys are scoped to a deck,
+// so the same key can be used with different decks. Requests creating decks have an empty DeckID.
+type IdempotencyKey struct {
+	DeckID string
+	Key    string
+}
+
+// IdempotentResponse is the response stored for an idempotency key, replayed when the request is retried.
+type IdempotentResponse struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body"`
+}
+
+// IdempotencyRecord is what is stored for an idempotency key.
+type IdempotencyRecord struct {
+	// Fingerprint of the request that reserved the key, so retries with a different request are detected.
+	Fingerprint string
+	// Response of the request, nil while the request is in progress.
+	Response *IdempotentResponse
+	// ExpiresAt is the time after which the key is forgotten and can be reserved again.
+	ExpiresAt time.Time
+}
+
+// IdempotencyStore represents the interface required for storing the responses of the requests with an
+// idempotency key. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Reserve stores a record in progress for the key with the given fingerprint, expiring after the ttl, and
+	// returns it along with true. If the key has a record not expired at the given time, it is returned along
+	// with false instead, leaving it untouched.
+	Reserve(ctx context.Context, key IdempotencyKey, fingerprint string, now time.Time, ttl time.Duration) (IdempotencyRecord, bool, error)
+	// Complete stores the response of the request that reserved the key.
+	Complete(ctx context.Context, key IdempotencyKey, res IdempotentResponse) error
+	// Release forgets the key, so the request can be retried from scratch.
+	Release(ctx context.Context, key IdempotencyKey) error
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// CloseDeck closes the deck with the given UUID, so it can still be viewed but not modified anymore, and returns the
+// deck as viewed by the player in the context. Returns an error if there is no deck with the given UUID, if it is
+// already closed or if saving the modified deck failed.
+func (s *DeckService) CloseDeck(ctx context.Context, uuid string) (OpenDeckOutput, error) {
+	d, err := s.modifyDeck(ctx, uuid, func(d *domain.Deck) error {
+		now := s.now().UTC()
+
+		if err := d.Close(now); err != nil {
+			return err
+		}
+
+		d.Record(domain.ClosedEvent, now, RequestIDFromContext(ctx), PlayerFromContext(ctx))
+
+		return nil
+	})
+
+	if err != nil {
+		return OpenDeckOutput{}, err
+	}
+
+	return openDeckOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// DeleteDeck deletes the deck with the given UUID along with its piles, hands and history. Returns an error if there
+// is no deck with the given UUID, if it isn't at the version expected in the context or if deleting it failed.
+func (s *DeckService) DeleteDeck(ctx context.Context, uuid string) error {
+	unlock := s.locks.lock(uuid)
+	defer unlock()
+
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkExpectedVersion(ctx, d); err != nil {
+		return err
+	}
+
+	if err := s.deckRepository.Delete(ctx, uuid); err != nil {
+		return fmt.Errorf("deleting the deck failed: %w", err)
+	}
+
+	return nil
+}
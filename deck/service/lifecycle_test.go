@@ -0,0 +1,86 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+)
+
+func TestDeckService_CloseDeck(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository())
+	created, err := s.CreateDeck(ctx)
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+
+	got, err := s.CloseDeck(ctx, created.DeckID)
+	if err != nil {
+		t.Fatalf("DeckService.CloseDeck() error = %v", err)
+	}
+	if got.ClosedAt == nil || got.Remaining != 52 || got.Version != 2 {
+		t.Errorf("DeckService.CloseDeck() = %+v, want the complete deck closed at version 2", got)
+	}
+
+	if _, err := s.DrawCards(ctx, created.DeckID, 1); !errors.Is(err, domain.ErrDeckClosed) {
+		t.Errorf("DeckService.DrawCards() error = %v, want %v", err, domain.ErrDeckClosed)
+	}
+	if _, err := s.CloseDeck(ctx, created.DeckID); !errors.Is(err, domain.ErrDeckClosed) {
+		t.Errorf("DeckService.CloseDeck() error = %v, want %v", err, domain.ErrDeckClosed)
+	}
+	if opened, err := s.OpenDeck(ctx, created.DeckID); err != nil || opened.ClosedAt == nil {
+		t.Errorf("DeckService.OpenDeck() = %+v, %v, want the closed deck", opened, err)
+	}
+	history, _ := s.History(ctx, created.DeckID)
+	if last := history.Events[len(history.Events)-1]; last.Type != domain.ClosedEvent {
+		t.Errorf("DeckService.History() last event = %+v, want the closed event", last)
+	}
+}
+
+func TestDeckService_DeleteDeck(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		deleteErr   error
+		wantDeleted bool
+		wantErr     error
+	}{
+		{name: "deletes the deck", ctx: ctx, wantDeleted: true},
+		{
+			name:    "returns an error if the deck isn't at the expected version",
+			ctx:     service.ContextWithExpectedVersion(ctx, 2),
+			wantErr: service.ErrVersionMismatch,
+		},
+		{
+			name:        "returns an error if deleting the deck fails",
+			ctx:         ctx,
+			deleteErr:   domain.ErrDeckNotFound,
+			wantDeleted: true,
+			wantErr:     domain.ErrDeckNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", tt.ctx, uuid).Return(&domain.Deck{UUID: uuid, Version: 1}, nil)
+			m.On("Delete", tt.ctx, uuid).Return(tt.deleteErr)
+			err := service.NewDeckService(m).DeleteDeck(tt.ctx, uuid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.DeleteDeck() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantDeleted {
+				m.AssertCalled(t, "Delete", tt.ctx, uuid)
+			} else {
+				m.AssertNotCalled(t, "Delete", tt.ctx, uuid)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// Bounds of the number of decks listed per page.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+var (
+	// ErrInvalidCursor error returned when listing decks from a cursor that wasn't returned by a previous listing.
+	ErrInvalidCursor = errors.New("invalid_cursor")
+	// ErrInvalidLimit error returned when listing a number of decks per page outside of [1, MaxListLimit].
+	ErrInvalidLimit = errors.New("invalid_limit")
+)
+
+// DeckFilter selects the decks to list. The zero value selects every deck.
+type DeckFilter struct {
+	// CreatedAfter selects the decks created after the given time, if it isn't zero.
+	CreatedAfter time.Time
+	// Shuffled selects the decks shuffled or not, if it isn't nil.
+	Shuffled *bool
+	// MinRemaining and MaxRemaining select the decks with at least and at most the given remaining cards, if they
+	// aren't nil.
+	MinRemaining *int
+	MaxRemaining *int
+	// Owner selects the decks created by the given player, if it isn't empty.
+	Owner string
+	// Tags selects the decks having every one of the given tags.
+	Tags []string
+}
+
+// Matches returns whether the filter selects the deck.
+func (f DeckFilter) Matches(d *domain.Deck) bool {
+	switch {
+	case !f.CreatedAfter.IsZero() && !d.CreatedAt.After(f.CreatedAfter),
+		f.Shuffled != nil && d.Shuffled != *f.Shuffled,
+		f.MinRemaining != nil && len(d.Cards) < *f.MinRemaining,
+		f.MaxRemaining != nil && len(d.Cards) > *f.MaxRemaining,
+		f.Owner != "" && d.Owner != f.Owner:
+		return false
+	default:
+		return d.HasTags(f.Tags)
+	}
+}
+
+// DeckCursor is the position of a deck in the listing order, which is by creation time and then by UUID.
+type DeckCursor struct {
+	CreatedAt time.Time
+	UUID      string
+}
+
+// Precedes returns whether the deck summarized comes after the cursor in the listing order.
+func (c DeckCursor) Precedes(s DeckSummary) bool {
+	return c.CreatedAt.Before(s.CreatedAt) || (c.CreatedAt.Equal(s.CreatedAt) && c.UUID < s.DeckID)
+}
+
+// DeckQuery describes a page of decks to list.
+type DeckQuery struct {
+	Filter DeckFilter
+	// After is the position after which the decks are listed, nil to list from the first deck.
+	After *DeckCursor
+	// Limit is the maximum number of decks to list.
+	Limit int
+}
+
+// DeckSummary describes a deck without its cards.
+type DeckSummary struct {
+	DeckID    string     `json:"deck_id"`
+	Version   int64      `json:"version"`
+	Shuffled  bool       `json:"shuffled"`
+	Remaining int        `json:"remaining"`
+	Owner     string     `json:"owner,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// SummarizeDeck returns the summary of the deck.
+func SummarizeDeck(d *domain.Deck) DeckSummary {
+	return DeckSummary{
+		DeckID:    d.UUID,
+		Version:   d.Version,
+		Shuffled:  d.Shuffled,
+		Remaining: len(d.Cards),
+		Owner:     d.Owner,
+		Tags:      d.Tags,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+		ExpiresAt: d.ExpiresAt,
+		ClosedAt:  d.ClosedAt,
+	}
+}
+
+// ListDecksOutput is a page of the listed decks.
+type ListDecksOutput struct {
+	Decks []DeckSummary `json:"decks"`
+	// NextCursor is the cursor to list the next page of decks, empty if this is the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListDecks lists the decks selected by the filter, ordered by creation time, in pages of up to limit decks, or
+// DefaultListLimit if it is 0. The first page is listed with an empty cursor, and the following ones with the cursor
+// returned along with the previous page. Returns an error if the cursor or the limit are invalid or if the decks
+// couldn't be listed.
+func (s *DeckService) ListDecks(ctx context.Context, filter DeckFilter, cursor string, limit int) (ListDecksOutput, error) {
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	if limit < 1 || limit > MaxListLimit {
+		return ListDecksOutput{}, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidLimit, MaxListLimit)
+	}
+
+	q := DeckQuery{Filter: filter, Limit: limit + 1}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+
+		if err != nil {
+			return ListDecksOutput{}, err
+		}
+
+		q.After = &after
+	}
+
+	decks, err := s.deckRepository.List(ctx, q)
+
+	if err != nil {
+		return ListDecksOutput{}, fmt.Errorf("listing the decks failed: %w", err)
+	}
+
+	out := ListDecksOutput{Decks: decks}
+
+	// One more deck than the limit is listed to know whether there is a next page.
+	if len(decks) > limit {
+		out.Decks = decks[:limit]
+		last := out.Decks[limit-1]
+		out.NextCursor = encodeCursor(DeckCursor{CreatedAt: last.CreatedAt, UUID: last.DeckID})
+	}
+
+	if out.Decks == nil {
+		out.Decks = []DeckSummary{}
+	}
+
+	return out, nil
+}
+
+// encodeCursor returns the cursor as an opaque string, made of its creation time in nanoseconds and its UUID.
+func encodeCursor(c DeckCursor) string {
+	var nanos int64
+
+	if !c.CreatedAt.IsZero() {
+		nanos = c.CreatedAt.UnixNano()
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(nanos, 10) + "/" + c.UUID))
+}
+
+// decodeCursor returns the cursor encoded in the string. Returns an error if it isn't a cursor.
+func decodeCursor(str string) (DeckCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(str)
+
+	if err != nil {
+		return DeckCursor{}, ErrInvalidCursor
+	}
+
+	nanosStr, uuid, ok := strings.Cut(string(data), "/")
+
+	if !ok || uuid == "" {
+		return DeckCursor{}, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(nanosStr, 10, 64)
+
+	if err != nil {
+		return DeckCursor{}, ErrInvalidCursor
+	}
+
+	c := DeckCursor{UUID: uuid}
+
+	if nanos != 0 {
+		c.CreatedAt = time.Unix(0, nanos).UTC()
+	}
+
+	return c, nil
+}
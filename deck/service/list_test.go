@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+func TestDeckService_ListDecks(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(), service.WithClock(clock.Now))
+
+	var created []string
+	for _, player := range []string{"alice", "bob", "alice"} {
+		clock.Advance(time.Second)
+		d, err := s.CreateDeck(service.ContextWithPlayer(ctx, player), service.WithTags("poker", player))
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		if d.Owner != player || !reflect.DeepEqual(d.Tags, []string{player, "poker"}) {
+			t.Errorf("DeckService.CreateDeck() = %+v, want it owned by %s and tagged", d, player)
+		}
+		created = append(created, d.DeckID)
+	}
+
+	t.Run("lists every page", func(t *testing.T) {
+		var (
+			listed []string
+			cursor string
+			pages  int
+		)
+		for {
+			page, err := s.ListDecks(ctx, service.DeckFilter{}, cursor, 2)
+			if err != nil {
+				t.Fatalf("DeckService.ListDecks() error = %v", err)
+			}
+			pages++
+			for _, d := range page.Decks {
+				listed = append(listed, d.DeckID)
+			}
+			if cursor = page.NextCursor; cursor == "" {
+				break
+			}
+		}
+		if pages != 2 || !reflect.DeepEqual(listed, created) {
+			t.Errorf("DeckService.ListDecks() = %v in %d pages, want %v in 2 pages", listed, pages, created)
+		}
+	})
+
+	t.Run("filters the decks", func(t *testing.T) {
+		got, err := s.ListDecks(ctx, service.DeckFilter{Owner: "alice", Tags: []string{"poker"}}, "", 0)
+		if err != nil {
+			t.Fatalf("DeckService.ListDecks() error = %v", err)
+		}
+		if len(got.Decks) != 2 || got.Decks[0].DeckID != created[0] || got.Decks[1].DeckID != created[2] || got.NextCursor != "" {
+			t.Errorf("DeckService.ListDecks() = %+v, want the decks of alice", got)
+		}
+	})
+
+	t.Run("lists an empty page", func(t *testing.T) {
+		got, err := s.ListDecks(ctx, service.DeckFilter{Owner: "carol"}, "", 0)
+		if err != nil {
+			t.Fatalf("DeckService.ListDecks() error = %v", err)
+		}
+		if want := (service.ListDecksOutput{Decks: []service.DeckSummary{}}); !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.ListDecks() = %+v, want %+v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		cursor  string
+		limit   int
+		wantErr error
+	}{
+		{name: "invalid cursor", cursor: "not-a-cursor", wantErr: service.ErrInvalidCursor},
+		{name: "limit too big", limit: service.MaxListLimit + 1, wantErr: service.ErrInvalidLimit},
+		{name: "negative limit", limit: -1, wantErr: service.ErrInvalidLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.ListDecks(ctx, service.DeckFilter{}, tt.cursor, tt.limit); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.ListDecks() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("returns an error if a tag is invalid", func(t *testing.T) {
+		if _, err := s.CreateDeck(ctx, service.WithTags("two words")); !errors.Is(err, domain.ErrInvalidTag) {
+			t.Errorf("DeckService.CreateDeck() error = %v, want %v", err, domain.ErrInvalidTag)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package service
+
+import "sync"
+
+// deckLocks serializes the operations over the same deck, while allowing operations over different decks
+// to run concurrently. Locks are released from memory once nobody is holding or waiting for them.
+type deckLocks struct {
+	mu    sync.Mutex
+	locks map[string]*deckLock
+}
+
+type deckLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newDeckLocks() *deckLocks {
+	return &deckLocks{
+		locks: make(map[string]*deckLock),
+	}
+}
+
+// lock blocks until the lock of the deck with the given UUID is acquired. Returns the function to release it.
+func (l *deckLocks) lock(uuid string) func() {
+	l.mu.Lock()
+	dl, ok := l.locks[uuid]
+
+	if !ok {
+		dl = &deckLock{}
+		l.locks[uuid] = dl
+	}
+
+	dl.refs++
+	l.mu.Unlock()
+
+	dl.Lock()
+
+	return func() {
+		dl.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		dl.refs--
+
+		if dl.refs == 0 {
+			delete(l.locks, uuid)
+		}
+	}
+}
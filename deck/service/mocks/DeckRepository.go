@@ -7,6 +7,10 @@ import (
 
 	domain "github.com/cfagudelo96/toggle-test/deck/domain"
 	mock "github.com/stretchr/testify/mock"
+
+	service "github.com/cfagudelo96/toggle-test/deck/service"
+
+	time "time"
 )
 
 // DeckRepository is an autogenerated mock type for the DeckRepository type
@@ -14,6 +18,20 @@ type DeckRepository struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: ctx, uuid
+func (_m *DeckRepository) Delete(ctx context.Context, uuid string) error {
+	ret := _m.Called(ctx, uuid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uuid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, uuid
 func (_m *DeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, error) {
 	ret := _m.Called(ctx, uuid)
@@ -37,6 +55,52 @@ func (_m *DeckRepository) Get(ctx context.Context, uuid string) (*domain.Deck, e
 	return r0, r1
 }
 
+// History provides a mock function with given fields: ctx, uuid
+func (_m *DeckRepository) History(ctx context.Context, uuid string) (domain.History, error) {
+	ret := _m.Called(ctx, uuid)
+
+	var r0 domain.History
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.History); ok {
+		r0 = rf(ctx, uuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(domain.History)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, q
+func (_m *DeckRepository) List(ctx context.Context, q service.DeckQuery) ([]service.DeckSummary, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 []service.DeckSummary
+	if rf, ok := ret.Get(0).(func(context.Context, service.DeckQuery) []service.DeckSummary); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]service.DeckSummary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, service.DeckQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, d
 func (_m *DeckRepository) Save(ctx context.Context, d *domain.Deck) error {
 	ret := _m.Called(ctx, d)
@@ -50,3 +114,24 @@ func (_m *DeckRepository) Save(ctx context.Context, d *domain.Deck) error {
 
 	return r0
 }
+
+// Sweep provides a mock function with given fields: ctx, now, idleBefore
+func (_m *DeckRepository) Sweep(ctx context.Context, now time.Time, idleBefore time.Time) (int, error) {
+	ret := _m.Called(ctx, now, idleBefore)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) int); ok {
+		r0 = rf(ctx, now, idleBefore)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, now, idleBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// OperationOutput is the result of an operation changing the order of a deck.
+type OperationOutput struct {
+	DeckID    string           `json:"deck_id"`
+	Version   int64            `json:"version"`
+	Remaining int              `json:"remaining"`
+	Operation domain.Operation `json:"operation"`
+}
+
+// operationOutputFromDeck returns the last operation applied to the deck, without its seed if the player can't see
+// the remaining cards.
+func operationOutputFromDeck(d *domain.Deck, player string) OperationOutput {
+	_, hidden := viewCards(d.Cards, d.EffectiveVisibility(), player)
+
+	return OperationOutput{
+		DeckID:    d.UUID,
+		Version:   d.Version,
+		Remaining: len(d.Cards),
+		Operation: viewOperations(d.Operations[len(d.Operations)-1:], hidden)[0],
+	}
+}
+
+// CutDeck cuts the deck with the given UUID at the given index, or at a random one taken from the seed source of
+// the service if no index is given. Returns an error if there is no deck with the given UUID, if the deck is
+// provably fair, if the index is outside of the deck or if saving the modified deck failed.
+func (s *DeckService) CutDeck(ctx context.Context, uuid string, index *int) (OperationOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.CutEvent, func(d *domain.Deck) error {
+		if err := checkReorderable(d); err != nil {
+			return err
+		}
+
+		if index == nil {
+			d.CutRandom(s.nextSeed())
+			return nil
+		}
+
+		return d.Cut(*index)
+	})
+
+	if err != nil {
+		return OperationOutput{}, err
+	}
+
+	return operationOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// RiffleShuffle riffle shuffles the deck with the given UUID the given number of times, with a seed taken from the
+// seed source of the service. Returns an error if there is no deck with the given UUID, if the deck is provably fair,
+// if the times are invalid or if saving the modified deck failed.
+func (s *DeckService) RiffleShuffle(ctx context.Context, uuid string, times int) (OperationOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.RiffledEvent, func(d *domain.Deck) error {
+		if err := checkReorderable(d); err != nil {
+			return err
+		}
+
+		return d.Riffle(times, s.nextSeed())
+	})
+
+	if err != nil {
+		return OperationOutput{}, err
+	}
+
+	return operationOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
+
+// OverhandShuffle overhand shuffles the deck with the given UUID the given number of times, with a seed taken from
+// the seed source of the service. Returns an error if there is no deck with the given UUID, if the deck is provably
+// fair, if the times are invalid or if saving the modified deck failed.
+func (s *DeckService) OverhandShuffle(ctx context.Context, uuid string, times int) (OperationOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.OverhandedEvent, func(d *domain.Deck) error {
+		if err := checkReorderable(d); err != nil {
+			return err
+		}
+
+		return d.Overhand(times, s.nextSeed())
+	})
+
+	if err != nil {
+		return OperationOutput{}, err
+	}
+
+	return operationOutputFromDeck(d, PlayerFromContext(ctx)), nil
+}
@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeckService_CutDeck(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	index := func(i int) *int { return &i }
+	tests := []struct {
+		name    string
+		index   *int
+		want    service.OperationOutput
+		wantErr error
+	}{
+		{
+			name:  "cuts at the given index",
+			index: index(1),
+			want:  service.OperationOutput{DeckID: uuid, Remaining: 2, Operation: domain.Operation{Type: domain.CutOperation, Index: 1}},
+		},
+		{
+			name: "cuts at a random index with a seed from the seed source",
+			want: service.OperationOutput{DeckID: uuid, Remaining: 2, Operation: domain.Operation{Type: domain.CutOperation, Index: 1, Seed: 42}},
+		},
+		{
+			name:    "returns an error if the index is outside of the deck",
+			index:   index(3),
+			wantErr: domain.ErrInvalidCutIndex,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", ctx, uuid).Return(&domain.Deck{
+				UUID:  uuid,
+				Cards: []domain.Card{{Rank: domain.Four, Suit: domain.Hearts}, {Rank: domain.Five, Suit: domain.Spades}},
+			}, nil)
+			m.On("Save", ctx, mock.Anything).Return(nil)
+			s := service.NewDeckService(m, service.WithSeedSource(fixedSeedSource(42)))
+			got, err := s.CutDeck(ctx, uuid, tt.index)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.CutDeck() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeckService.CutDeck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeckService_RiffleShuffle(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	m := &mocks.DeckRepository{}
+	m.On("Get", ctx, uuid).Return(&domain.Deck{UUID: uuid, Cards: domain.CompleteDeckCards()}, nil)
+	m.On("Save", ctx, mock.Anything).Return(nil)
+
+	got, err := service.NewDeckService(m, service.WithSeedSource(fixedSeedSource(42))).RiffleShuffle(ctx, uuid, 7)
+	want := service.OperationOutput{DeckID: uuid, Remaining: 52, Operation: domain.Operation{Type: domain.RiffleOperation, Times: 7, Seed: 42}}
+	if err != nil {
+		t.Fatalf("DeckService.RiffleShuffle() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeckService.RiffleShuffle() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// PileSummary summarizes a pile of a deck.
+type PileSummary struct {
+	Name      string `json:"name"`
+	Remaining int    `json:"remaining"`
+}
+
+func pileSummaries(d *domain.Deck) []PileSummary {
+	if len(d.Piles) == 0 {
+		return nil
+	}
+
+	summaries := make([]PileSummary, len(d.Piles))
+
+	for i, p := range d.Piles {
+		summaries[i] = PileSummary{Name: p.Name, Remaining: len(p.Cards)}
+	}
+
+	return summaries
+}
+
+// PileOutput is the result of the operations over a pile of a deck.
+type PileOutput struct {
+	DeckID    string        `json:"deck_id"`
+	Version   int64         `json:"version"`
+	Pile      string        `json:"pile"`
+	Remaining int           `json:"remaining"`
+	Cards     []domain.Card `json:"cards"`
+	Hidden    bool          `json:"hidden,omitempty"`
+}
+
+// pileOutputFromDeck returns the pile as viewed by the player, hiding its cards if the player can't see them.
+func pileOutputFromDeck(d *domain.Deck, name, player string) (PileOutput, error) {
+	p, err := d.Pile(name)
+
+	if err != nil {
+		return PileOutput{}, err
+	}
+
+	cards, hidden := viewCards(p.Cards, p.EffectiveVisibility(), player)
+
+	return PileOutput{
+		DeckID:    d.UUID,
+		Version:   d.Version,
+		Pile:      p.Name,
+		Remaining: len(p.Cards),
+		Cards:     cards,
+		Hidden:    hidden,
+	}, nil
+}
+
+// AddToPile places the given cards, previously drawn from the deck with the given UUID, on top of the pile.
+// The pile is created if the deck doesn't have it.
+// Returns an error if there is no deck with the given UUID, if any of the cards wasn't drawn or is already in a pile,
+// or if saving the modified deck failed.
+func (s *DeckService) AddToPile(ctx context.Context, uuid, pile string, cards []domain.Card) (PileOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.AddedToPileEvent, func(d *domain.Deck) error {
+		return d.AddToPile(pile, cards)
+	})
+
+	if err != nil {
+		return PileOutput{}, err
+	}
+
+	return pileOutputFromDeck(d, pile, PlayerFromContext(ctx))
+}
+
+// ListPile lists the cards of the pile of the deck with the given UUID, as viewed by the player in the context.
+// Returns an error if there is no deck with the given UUID or if the deck doesn't have the pile.
+func (s *DeckService) ListPile(ctx context.Context, uuid, pile string) (PileOutput, error) {
+	d, err := s.getDeck(ctx, uuid)
+
+	if err != nil {
+		return PileOutput{}, err
+	}
+
+	return pileOutputFromDeck(d, pile, PlayerFromContext(ctx))
+}
+
+// DrawFromPile draws the given amount of cards from the top of the pile of the deck with the given UUID.
+// Returns an error if there is no deck with the given UUID, if the deck doesn't have the pile or if saving
+// the modified deck failed.
+func (s *DeckService) DrawFromPile(ctx context.Context, uuid, pile string, amount int) (DrawCardsOutput, error) {
+	var drawnCards []domain.Card
+
+	d, err := s.updateDeck(ctx, uuid, domain.DrawnFromPileEvent, func(d *domain.Deck) (err error) {
+		drawnCards, err = d.DrawFromPile(pile, amount)
+		return err
+	})
+
+	if err != nil {
+		return DrawCardsOutput{}, err
+	}
+
+	return DrawCardsOutput{Version: d.Version, Cards: drawnCards}, nil
+}
+
+// ShufflePile shuffles the pile of the deck with the given UUID, following the shuffle strategy of the deck.
+// Returns an error if there is no deck with the given UUID, if the deck doesn't have the pile or if saving
+// the modified deck failed.
+func (s *DeckService) ShufflePile(ctx context.Context, uuid, pile string) (PileOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.PileShuffledEvent, func(d *domain.Deck) error {
+		return d.ShufflePile(pile, s.shufflerFor(d))
+	})
+
+	if err != nil {
+		return PileOutput{}, err
+	}
+
+	return pileOutputFromDeck(d, pile, PlayerFromContext(ctx))
+}
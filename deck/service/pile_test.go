@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeckService_AddToPile(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	aceSpades := domain.Card{Rank: domain.Ace, Suit: domain.Spades}
+	tests := []struct {
+		name    string
+		deck    *domain.Deck
+		cards   []domain.Card
+		want    service.PileOutput
+		wantErr error
+	}{
+		{
+			name:  "places the drawn cards in the pile",
+			deck:  &domain.Deck{UUID: uuid, Drawn: []domain.Card{aceSpades}},
+			cards: []domain.Card{aceSpades},
+			want: service.PileOutput{
+				DeckID:    uuid,
+				Pile:      "discard",
+				Remaining: 1,
+				Cards:     []domain.Card{aceSpades},
+			},
+		},
+		{
+			name:    "returns an error if the cards weren't drawn",
+			deck:    &domain.Deck{UUID: uuid},
+			cards:   []domain.Card{aceSpades},
+			wantErr: domain.ErrCardsNotDrawn,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", ctx, uuid).Return(tt.deck, nil)
+			m.On("Save", ctx, mock.Anything).Return(nil)
+			got, err := service.NewDeckService(m).AddToPile(ctx, uuid, "discard", tt.cards)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.AddToPile() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeckService.AddToPile() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr != nil {
+				m.AssertNotCalled(t, "Save", ctx, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeckService_ListPile(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	m := &mocks.DeckRepository{}
+	m.On("Get", ctx, uuid).Return(&domain.Deck{
+		UUID:  uuid,
+		Piles: []*domain.Pile{{Name: "hand", Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Clubs}}}},
+	}, nil)
+	s := service.NewDeckService(m)
+
+	t.Run("lists the cards of the pile", func(t *testing.T) {
+		got, err := s.ListPile(ctx, uuid, "hand")
+		want := service.PileOutput{DeckID: uuid, Pile: "hand", Remaining: 1, Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Clubs}}}
+		if err != nil {
+			t.Fatalf("DeckService.ListPile() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.ListPile() = %v, want %v", got, want)
+		}
+	})
+	t.Run("returns an error if the pile doesn't exist", func(t *testing.T) {
+		if _, err := s.ListPile(ctx, uuid, "discard"); !errors.Is(err, domain.ErrPileNotFound) {
+			t.Errorf("DeckService.ListPile() error = %v, want %v", err, domain.ErrPileNotFound)
+		}
+	})
+}
+
+func TestDeckService_DrawFromPile(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	m := &mocks.DeckRepository{}
+	m.On("Get", ctx, uuid).Return(&domain.Deck{
+		UUID:  uuid,
+		Piles: []*domain.Pile{{Name: "hand", Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Clubs}, {Rank: domain.Three, Suit: domain.Clubs}}}},
+	}, nil)
+	m.On("Save", ctx, mock.Anything).Return(nil)
+
+	got, err := service.NewDeckService(m).DrawFromPile(ctx, uuid, "hand", 1)
+	want := service.DrawCardsOutput{Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Clubs}}}
+	if err != nil {
+		t.Fatalf("DeckService.DrawFromPile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeckService.DrawFromPile() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeckService_ReturnCards(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	aceSpades := domain.Card{Rank: domain.Ace, Suit: domain.Spades}
+	twoClubs := domain.Card{Rank: domain.Two, Suit: domain.Clubs}
+	tests := []struct {
+		name    string
+		cards   []domain.Card
+		want    service.OpenDeckOutput
+		wantErr error
+	}{
+		{
+			name:  "returns the drawn cards to the deck",
+			cards: []domain.Card{aceSpades},
+			want: service.OpenDeckOutput{
+				DeckID:    uuid,
+				Remaining: 2,
+				Cards:     []domain.Card{twoClubs, aceSpades},
+			},
+		},
+		{
+			name:    "returns an error if the cards don't belong to the deck",
+			cards:   []domain.Card{domain.NewJoker()},
+			wantErr: domain.ErrCardsNotDrawn,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mocks.DeckRepository{}
+			m.On("Get", ctx, uuid).Return(&domain.Deck{
+				UUID:  uuid,
+				Cards: []domain.Card{twoClubs},
+				Drawn: []domain.Card{aceSpades},
+			}, nil)
+			m.On("Save", ctx, mock.Anything).Return(nil)
+			got, err := service.NewDeckService(m).ReturnCards(ctx, uuid, tt.cards, domain.PositionBottom)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.ReturnCards() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeckService.ReturnCards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeckService_ResetDeck(t *testing.T) {
+	ctx := context.Background()
+	uuid := "some-deck-uuid"
+	m := &mocks.DeckRepository{}
+	m.On("Get", ctx, uuid).Return(&domain.Deck{
+		UUID:  uuid,
+		Cards: []domain.Card{{Rank: domain.Two, Suit: domain.Clubs}},
+		Drawn: []domain.Card{{Rank: domain.Ace, Suit: domain.Spades}},
+		Piles: []*domain.Pile{{Name: "discard", Cards: []domain.Card{{Rank: domain.King, Suit: domain.Hearts}}}},
+	}, nil)
+	m.On("Save", ctx, mock.Anything).Return(nil)
+
+	got, err := service.NewDeckService(m, service.WithSeedSource(fixedSeedSource(42))).ResetDeck(ctx, uuid)
+	if err != nil {
+		t.Fatalf("DeckService.ResetDeck() error = %v", err)
+	}
+	if got.Remaining != 3 || !got.Shuffled || got.Piles != nil {
+		t.Errorf("DeckService.ResetDeck() = %v, want 3 shuffled cards without piles", got)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// SweepDecks deletes the decks that expired, along with the exhausted or closed decks that weren't changed for the
+// given idle time, and returns how many were deleted. Returns an error if the decks couldn't be deleted.
+func (s *DeckService) SweepDecks(ctx context.Context, idle time.Duration) (int, error) {
+	now := s.now()
+
+	swept, err := s.deckRepository.Sweep(ctx, now, now.Add(-idle))
+
+	if err != nil {
+		return 0, fmt.Errorf("sweeping the decks failed: %w", err)
+	}
+
+	return swept, nil
+}
+
+// Sweeper sweeps the decks of a DeckService periodically in the background, until it is closed.
+type Sweeper struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartSweeper starts sweeping the decks every interval, deleting the decks that expired along with the exhausted or
+// closed decks that weren't changed for the given idle time. Errors are logged and the sweeper keeps going.
+// The returned sweeper must be closed to stop it.
+func (s *DeckService) StartSweeper(interval, idle time.Duration) *Sweeper {
+	sw := &Sweeper{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(sw.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-sw.stop:
+				return
+			case <-ticker.C:
+				if _, err := s.SweepDecks(context.Background(), idle); err != nil {
+					log.Printf("Error sweeping the decks: %v", err)
+				}
+			}
+		}
+	}()
+
+	return sw
+}
+
+// Close stops the sweeper, waiting for the sweep in progress if any. It is safe to call it more than once.
+func (sw *Sweeper) Close() error {
+	sw.stopOnce.Do(func() { close(sw.stop) })
+	<-sw.done
+
+	return nil
+}
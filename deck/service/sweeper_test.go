@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+// testClock is a clock that only moves when told to. It is safe for concurrent use.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestDeckService_Expiration(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(), service.WithClock(clock.Now),
+		service.WithDefaultTTL(time.Hour))
+
+	byDefault, err := s.CreateDeck(ctx)
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	if want := clock.Now().Add(time.Hour); byDefault.ExpiresAt == nil || !byDefault.ExpiresAt.Equal(want) {
+		t.Errorf("DeckService.CreateDeck() expires at %v, want %v", byDefault.ExpiresAt, want)
+	}
+	longer, err := s.CreateDeck(ctx, service.WithTTL(3*time.Hour))
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	if _, err := s.CreateDeck(ctx, service.WithTTL(0)); !errors.Is(err, domain.ErrInvalidTTL) {
+		t.Errorf("DeckService.CreateDeck() error = %v, want %v", err, domain.ErrInvalidTTL)
+	}
+
+	clock.Advance(2 * time.Hour)
+
+	t.Run("expired decks can't be used", func(t *testing.T) {
+		if _, err := s.OpenDeck(ctx, byDefault.DeckID); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("DeckService.OpenDeck() error = %v, want %v", err, domain.ErrDeckExpired)
+		}
+		if _, err := s.DrawCards(ctx, byDefault.DeckID, 1); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("DeckService.DrawCards() error = %v, want %v", err, domain.ErrDeckExpired)
+		}
+		if _, err := s.DrawCards(ctx, longer.DeckID, 52); err != nil {
+			t.Errorf("DeckService.DrawCards() error = %v", err)
+		}
+	})
+	t.Run("sweeps the expired and the idle decks", func(t *testing.T) {
+		swept, err := s.SweepDecks(ctx, time.Hour)
+		if err != nil || swept != 1 {
+			t.Fatalf("DeckService.SweepDecks() = %d, %v, want 1 expired deck", swept, err)
+		}
+		clock.Advance(time.Hour)
+		swept, err = s.SweepDecks(ctx, time.Hour)
+		if err != nil || swept != 1 {
+			t.Fatalf("DeckService.SweepDecks() = %d, %v, want 1 idle deck", swept, err)
+		}
+		if _, err := s.OpenDeck(ctx, longer.DeckID); !errors.Is(err, domain.ErrDeckExpired) {
+			t.Errorf("DeckService.OpenDeck() error = %v, want %v", err, domain.ErrDeckExpired)
+		}
+	})
+}
+
+// sweepSignalingRepository signals every sweep of the wrapped repository.
+type sweepSignalingRepository struct {
+	*repository.InMemoryDeckRepository
+	swept chan int
+}
+
+func (r sweepSignalingRepository) Sweep(ctx context.Context, now, idleBefore time.Time) (int, error) {
+	n, err := r.InMemoryDeckRepository.Sweep(ctx, now, idleBefore)
+	select {
+	case r.swept <- n:
+	default:
+	}
+	return n, err
+}
+
+func TestDeckService_StartSweeper(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	r := sweepSignalingRepository{InMemoryDeckRepository: repository.NewInMemoryDeckRepository(), swept: make(chan int, 1)}
+	s := service.NewDeckService(r, service.WithClock(clock.Now))
+	created, err := s.CreateDeck(ctx, service.WithTTL(time.Minute))
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	clock.Advance(time.Minute)
+
+	sw := s.StartSweeper(time.Millisecond, time.Hour)
+	select {
+	case <-r.swept:
+	case <-time.After(5 * time.Second):
+		t.Fatal("DeckService.StartSweeper() didn't sweep the decks")
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Sweeper.Close() error = %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Sweeper.Close() twice error = %v", err)
+	}
+	if _, err := r.Get(ctx, created.DeckID); !errors.Is(err, domain.ErrDeckExpired) {
+		t.Errorf("DeckRepository.Get() error = %v, want %v", err, domain.ErrDeckExpired)
+	}
+}
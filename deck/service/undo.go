@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+// UndoOutput is the result of undoing operations on a deck.
+type UndoOutput struct {
+	OpenDeckOutput
+	// RevertedTo is the number of the event whose state the deck was reverted to.
+	RevertedTo int `json:"reverted_to"`
+	// Undoable is how many operations can still be undone.
+	Undoable int `json:"undoable"`
+}
+
+// UndoDeck reverts the deck with the given UUID to its state before its last operations, and returns the deck as
+// viewed by the player in the context. Returns an error if there is no deck with the given UUID, if the deck doesn't
+// allow undo, if the steps are invalid or if saving the modified deck failed.
+func (s *DeckService) UndoDeck(ctx context.Context, uuid string, steps int) (UndoOutput, error) {
+	var history domain.History
+
+	d, err := s.modifyDeck(ctx, uuid, func(d *domain.Deck) (err error) {
+		if history, err = s.deckRepository.History(ctx, uuid); err != nil {
+			return fmt.Errorf("getting the history failed: %w", err)
+		}
+
+		// The events recorded by the undo are cleared once saved, so they are kept along with the history.
+		if err := d.Undo(history, steps, s.now().UTC(), RequestIDFromContext(ctx), PlayerFromContext(ctx)); err != nil {
+			return err
+		}
+
+		history = append(history[:len(history):len(history)], d.Pending...)
+
+		return nil
+	})
+
+	if err != nil {
+		return UndoOutput{}, err
+	}
+
+	return UndoOutput{
+		OpenDeckOutput: openDeckOutputFromDeck(d, PlayerFromContext(ctx)),
+		RevertedTo:     history[len(history)-1].RevertedTo,
+		Undoable:       d.Undoable(history),
+	}, nil
+}
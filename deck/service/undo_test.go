@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+func TestDeckService_UndoDeck(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(), service.WithSeedSource(fixedSeedSource(42)))
+
+	t.Run("reverts a misclick", func(t *testing.T) {
+		created, err := s.CreateDeck(ctx)
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		if _, err := s.DrawCards(ctx, created.DeckID, 10); err != nil {
+			t.Fatalf("DeckService.DrawCards() error = %v", err)
+		}
+		got, err := s.UndoDeck(ctx, created.DeckID, 1)
+		if err != nil {
+			t.Fatalf("DeckService.UndoDeck() error = %v", err)
+		}
+		if got.Remaining != 52 || got.RevertedTo != 1 || got.Undoable != 0 {
+			t.Errorf("DeckService.UndoDeck() = %+v, want the complete deck reverted to its creation", got)
+		}
+		history, _ := s.History(ctx, created.DeckID)
+		if last := history.Events[len(history.Events)-1]; last.Type != domain.UndoneEvent || last.RevertedTo != 1 {
+			t.Errorf("DeckService.History() last event = %+v, want the undone event", last)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		opts    []service.DeckCreationOption
+		wantErr error
+	}{
+		{
+			name:    "undo disabled",
+			opts:    []service.DeckCreationOption{service.WithUndoDepth(0)},
+			wantErr: domain.ErrUndoDisabled,
+		},
+		{
+			name:    "provably fair decks can't be undone",
+			opts:    []service.DeckCreationOption{service.ProvablyFair("client")},
+			wantErr: domain.ErrUndoDisabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := s.CreateDeck(ctx, tt.opts...)
+			if err != nil {
+				t.Fatalf("DeckService.CreateDeck() error = %v", err)
+			}
+			if _, err := s.DrawCards(ctx, created.DeckID, 1); err != nil {
+				t.Fatalf("DeckService.DrawCards() error = %v", err)
+			}
+			if _, err := s.UndoDeck(ctx, created.DeckID, 1); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeckService.UndoDeck() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("provably fair decks can't be given an undo depth", func(t *testing.T) {
+		_, err := s.CreateDeck(ctx, service.ProvablyFair("client"), service.WithUndoDepth(3))
+		if !errors.Is(err, service.ErrInvalidOption) {
+			t.Errorf("DeckService.CreateDeck() error = %v, want %v", err, service.ErrInvalidOption)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+var (
+	// ErrVersionMismatch error returned when modifying a deck whose version isn't the one expected by the caller.
+	ErrVersionMismatch = errors.New("version_mismatch")
+)
+
+type expectedVersionContextKey struct{}
+
+// ContextWithExpectedVersion returns a copy of the context carrying the version the caller expects the deck to be at.
+// The operations modifying the deck fail with ErrVersionMismatch if the deck is at another version, so the caller
+// knows they would apply to a state of the deck it didn't see.
+func ContextWithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionContextKey{}, version)
+}
+
+// ExpectedVersionFromContext returns the version the caller expects the deck to be at, and whether there is one.
+func ExpectedVersionFromContext(ctx context.Context) (int64, bool) {
+	version, ok := ctx.Value(expectedVersionContextKey{}).(int64)
+	return version, ok
+}
+
+// checkExpectedVersion returns an error if the deck isn't at the version expected in the context, if any.
+func checkExpectedVersion(ctx context.Context, d *domain.Deck) error {
+	expected, ok := ExpectedVersionFromContext(ctx)
+
+	if ok && d.Version != expected {
+		return fmt.Errorf("%w: the deck is at version %d, not %d", ErrVersionMismatch, d.Version, expected)
+	}
+
+	return nil
+}
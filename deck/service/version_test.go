@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+)
+
+func TestDeckService_ExpectedVersion(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(), service.WithSeedSource(fixedSeedSource(42)))
+
+	created, err := s.CreateDeck(ctx)
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	if created.Version != 1 {
+		t.Errorf("DeckService.CreateDeck() version = %d, want 1", created.Version)
+	}
+
+	t.Run("modifies the deck at the expected version", func(t *testing.T) {
+		got, err := s.DrawCards(service.ContextWithExpectedVersion(ctx, 1), created.DeckID, 1)
+		if err != nil {
+			t.Fatalf("DeckService.DrawCards() error = %v", err)
+		}
+		if got.Version != 2 {
+			t.Errorf("DeckService.DrawCards() version = %d, want 2", got.Version)
+		}
+	})
+	t.Run("returns an error if the deck is at another version", func(t *testing.T) {
+		_, err := s.DrawCards(service.ContextWithExpectedVersion(ctx, 1), created.DeckID, 1)
+		if !errors.Is(err, service.ErrVersionMismatch) {
+			t.Errorf("DeckService.DrawCards() error = %v, want %v", err, service.ErrVersionMismatch)
+		}
+		opened, _ := s.OpenDeck(ctx, created.DeckID)
+		if opened.Version != 2 || opened.Remaining != 51 {
+			t.Errorf("DeckService.OpenDeck() = %+v, want the deck unchanged at version 2", opened)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+)
+
+var (
+	// ErrVisibilityNotAllowed error returned when the player making a request isn't allowed to change a visibility.
+	ErrVisibilityNotAllowed = errors.New("visibility_not_allowed")
+)
+
+type playerContextKey struct{}
+
+// ContextWithPlayer returns a copy of the context identifying the player the outputs are viewed by.
+// The cards the player can't see are hidden from the outputs.
+func ContextWithPlayer(ctx context.Context, player string) context.Context {
+	return context.WithValue(ctx, playerContextKey{}, player)
+}
+
+// PlayerFromContext returns the player the outputs are viewed by, empty for anyone not playing.
+func PlayerFromContext(ctx context.Context) string {
+	player, _ := ctx.Value(playerContextKey{}).(string)
+	return player
+}
+
+// viewCards returns the cards if the player can see them. Otherwise returns no cards and true, so only their
+// amount is exposed.
+func viewCards(cards []domain.Card, v domain.Visibility, player string) ([]domain.Card, bool) {
+	if v.VisibleTo(player) {
+		return cards, false
+	}
+
+	return []domain.Card{}, true
+}
+
+// viewOperations returns the operations applied to the remaining cards, without the seeds driving them if the cards
+// are hidden, as the seeds would give away their order.
+func viewOperations(ops []domain.Operation, hidden bool) []domain.Operation {
+	if !hidden {
+		return ops
+	}
+
+	viewed := make([]domain.Operation, len(ops))
+
+	for i, o := range ops {
+		o.Seed = 0
+		viewed[i] = o
+	}
+
+	return viewed
+}
+
+// checkVisibilityChange returns an error unless the player can change the visibility of the deck: only its owner
+// can, or the player of the given hand if any. Decks without an owner can only change the visibility of hands.
+func checkVisibilityChange(d *domain.Deck, player, hand string) error {
+	if player == "" || (player != d.Owner && player != hand) {
+		return ErrVisibilityNotAllowed
+	}
+
+	return nil
+}
+
+// VisibilityOutput is the result of changing the visibility of a part of a deck.
+type VisibilityOutput struct {
+	DeckID     string            `json:"deck_id"`
+	Version    int64             `json:"version"`
+	Visibility domain.Visibility `json:"visibility"`
+}
+
+// SetDeckVisibility sets the visibility of the remaining cards of the deck with the given UUID.
+// Returns an error if there is no deck with the given UUID, if the player in the context isn't its owner, if the
+// visibility is invalid or if saving the modified deck failed.
+func (s *DeckService) SetDeckVisibility(ctx context.Context, uuid string, v domain.Visibility) (VisibilityOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.VisibilityChangedEvent, func(d *domain.Deck) error {
+		if err := checkVisibilityChange(d, PlayerFromContext(ctx), ""); err != nil {
+			return err
+		}
+
+		return d.SetVisibility(v)
+	})
+
+	if err != nil {
+		return VisibilityOutput{}, err
+	}
+
+	return VisibilityOutput{DeckID: d.UUID, Version: d.Version, Visibility: v}, nil
+}
+
+// SetPileVisibility sets the visibility of the pile of the deck with the given UUID.
+// Returns an error if there is no deck with the given UUID, if the player in the context isn't its owner, if the
+// deck doesn't have the pile, if the visibility is invalid or if saving the modified deck failed.
+func (s *DeckService) SetPileVisibility(ctx context.Context, uuid, pile string, v domain.Visibility) (VisibilityOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.VisibilityChangedEvent, func(d *domain.Deck) error {
+		if err := checkVisibilityChange(d, PlayerFromContext(ctx), ""); err != nil {
+			return err
+		}
+
+		return d.SetPileVisibility(pile, v)
+	})
+
+	if err != nil {
+		return VisibilityOutput{}, err
+	}
+
+	return VisibilityOutput{DeckID: d.UUID, Version: d.Version, Visibility: v}, nil
+}
+
+// SetHandVisibility sets the visibility of the hand of the player in the deck with the given UUID.
+// Returns an error if there is no deck with the given UUID, if the player in the context is neither its owner nor
+// the player of the hand, if the player doesn't have a hand in the deck, if the visibility is invalid or if saving
+// the modified deck failed.
+func (s *DeckService) SetHandVisibility(ctx context.Context, uuid, player string, v domain.Visibility) (VisibilityOutput, error) {
+	d, err := s.updateDeck(ctx, uuid, domain.VisibilityChangedEvent, func(d *domain.Deck) error {
+		if err := checkVisibilityChange(d, PlayerFromContext(ctx), player); err != nil {
+			return err
+		}
+
+		return d.SetHandVisibility(player, v)
+	})
+
+	if err != nil {
+		return VisibilityOutput{}, err
+	}
+
+	return VisibilityOutput{DeckID: d.UUID, Version: d.Version, Visibility: v}, nil
+}
@@ -0,0 +1,253 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cfagudelo96/toggle-test/deck/domain"
+	"github.com/cfagudelo96/toggle-test/deck/repository"
+	"github.com/cfagudelo96/toggle-test/deck/service"
+	"github.com/cfagudelo96/toggle-test/deck/service/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeckService_Views(t *testing.T) {
+	uuid := "some-deck-uuid"
+	fourHearts := domain.Card{Rank: domain.Four, Suit: domain.Hearts}
+	fiveSpades := domain.Card{Rank: domain.Five, Suit: domain.Spades}
+	m := &mocks.DeckRepository{}
+	m.On("Get", mock.Anything, uuid).Return(&domain.Deck{
+		UUID:       uuid,
+		Cards:      []domain.Card{fourHearts},
+		Visibility: domain.Visibility{Mode: domain.FaceDown},
+		Piles: []*domain.Pile{{
+			Name:       "community",
+			Cards:      []domain.Card{fiveSpades},
+			Visibility: domain.Visibility{Mode: domain.VisibleToPlayers, Players: []string{"alice"}},
+		}},
+		Hands:      []*domain.Hand{{Player: "bob", Cards: []domain.Card{fiveSpades}}},
+		Operations: []domain.Operation{{Type: domain.RiffleOperation, Times: 7, Seed: 3}},
+	}, nil)
+	s := service.NewDeckService(m)
+	alice := service.ContextWithPlayer(context.Background(), "alice")
+	bob := service.ContextWithPlayer(context.Background(), "bob")
+
+	t.Run("hides the face down remaining cards", func(t *testing.T) {
+		got, err := s.OpenDeck(alice, uuid)
+		if err != nil {
+			t.Fatalf("DeckService.OpenDeck() error = %v", err)
+		}
+		if !got.Hidden || len(got.Cards) != 0 || got.Remaining != 1 {
+			t.Errorf("DeckService.OpenDeck() = %v, want only the amount of hidden cards", got)
+		}
+	})
+	t.Run("hides the seeds of the operations on face down cards", func(t *testing.T) {
+		got, err := s.OpenDeck(alice, uuid)
+		want := []domain.Operation{{Type: domain.RiffleOperation, Times: 7}}
+		if err != nil {
+			t.Fatalf("DeckService.OpenDeck() error = %v", err)
+		}
+		if !reflect.DeepEqual(got.Operations, want) {
+			t.Errorf("DeckService.OpenDeck() operations = %v, want %v", got.Operations, want)
+		}
+	})
+	t.Run("hides the peeked face down cards", func(t *testing.T) {
+		got, err := s.PeekCards(context.Background(), uuid, 1, domain.PositionTop)
+		want := service.PeekCardsOutput{DeckID: uuid, Remaining: 1, Cards: []domain.Card{}, Hidden: true}
+		if err != nil {
+			t.Fatalf("DeckService.PeekCards() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.PeekCards() = %v, want %v", got, want)
+		}
+	})
+	t.Run("shows a pile to the players listed", func(t *testing.T) {
+		got, err := s.ListPile(alice, uuid, "community")
+		want := service.PileOutput{DeckID: uuid, Pile: "community", Remaining: 1, Cards: []domain.Card{fiveSpades}}
+		if err != nil {
+			t.Fatalf("DeckService.ListPile() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.ListPile() = %v, want %v", got, want)
+		}
+	})
+	t.Run("hides a pile from other players", func(t *testing.T) {
+		got, err := s.ListPile(bob, uuid, "community")
+		want := service.PileOutput{DeckID: uuid, Pile: "community", Remaining: 1, Cards: []domain.Card{}, Hidden: true}
+		if err != nil {
+			t.Fatalf("DeckService.ListPile() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DeckService.ListPile() = %v, want %v", got, want)
+		}
+	})
+	t.Run("shows a hand only to its player", func(t *testing.T) {
+		own, err := s.ListHand(bob, uuid, "bob")
+		if err != nil {
+			t.Fatalf("DeckService.ListHand() error = %v", err)
+		}
+		other, err := s.ListHand(alice, uuid, "bob")
+		if err != nil {
+			t.Fatalf("DeckService.ListHand() error = %v", err)
+		}
+		if own.Hidden || !reflect.DeepEqual(own.Cards, []domain.Card{fiveSpades}) {
+			t.Errorf("DeckService.ListHand() for its player = %v, want the cards", own)
+		}
+		if !other.Hidden || len(other.Cards) != 0 || other.Remaining != 1 {
+			t.Errorf("DeckService.ListHand() for another player = %v, want only the amount of hidden cards", other)
+		}
+	})
+}
+
+func TestDeckService_Views_FaceUp(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository(), service.WithSeedSource(fixedSeedSource(42)))
+
+	t.Run("shows the peeked cards and the seeds of a face up deck", func(t *testing.T) {
+		created, err := s.CreateDeck(ctx)
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		if created.Seed == nil {
+			t.Errorf("DeckService.CreateDeck() = %v, want the seed", created)
+		}
+		if _, err := s.RiffleShuffle(ctx, created.DeckID, 1); err != nil {
+			t.Fatalf("DeckService.RiffleShuffle() error = %v", err)
+		}
+		got, err := s.PeekCards(ctx, created.DeckID, 2, domain.PositionTop)
+		if err != nil {
+			t.Fatalf("DeckService.PeekCards() error = %v", err)
+		}
+		if got.Hidden || len(got.Cards) != 2 {
+			t.Errorf("DeckService.PeekCards() = %v, want the 2 top cards", got)
+		}
+		opened, err := s.OpenDeck(ctx, created.DeckID)
+		if err != nil {
+			t.Fatalf("DeckService.OpenDeck() error = %v", err)
+		}
+		if len(opened.Operations) != 1 || opened.Operations[0].Seed != 42 {
+			t.Errorf("DeckService.OpenDeck() operations = %v, want the riffle with its seed", opened.Operations)
+		}
+	})
+	t.Run("hides the seeds of a face down deck", func(t *testing.T) {
+		created, err := s.CreateDeck(ctx, service.WithVisibility(domain.Visibility{Mode: domain.FaceDown}))
+		if err != nil {
+			t.Fatalf("DeckService.CreateDeck() error = %v", err)
+		}
+		if created.Seed != nil {
+			t.Errorf("DeckService.CreateDeck() seed = %d, want no seed", *created.Seed)
+		}
+		got, err := s.RiffleShuffle(ctx, created.DeckID, 1)
+		if err != nil {
+			t.Fatalf("DeckService.RiffleShuffle() error = %v", err)
+		}
+		if want := (domain.Operation{Type: domain.RiffleOperation, Times: 1}); got.Operation != want {
+			t.Errorf("DeckService.RiffleShuffle() operation = %v, want %v", got.Operation, want)
+		}
+	})
+}
+
+func TestDeckService_SetVisibility_Permissions(t *testing.T) {
+	s := service.NewDeckService(repository.NewInMemoryDeckRepository())
+	dealer := service.ContextWithPlayer(context.Background(), "dealer")
+	created, err := s.CreateDeck(dealer, service.Shuffled(false))
+	if err != nil {
+		t.Fatalf("DeckService.CreateDeck() error = %v", err)
+	}
+	uuid := created.DeckID
+	if _, err := s.DealCards(dealer, uuid, []string{"alice", "bob"}, 2); err != nil {
+		t.Fatalf("DeckService.DealCards() error = %v", err)
+	}
+	if _, err := s.AddToPile(dealer, uuid, "discard", nil); err != nil {
+		t.Fatalf("DeckService.AddToPile() error = %v", err)
+	}
+	faceUp := domain.Visibility{Mode: domain.FaceUp}
+
+	tests := []struct {
+		name    string
+		player  string
+		set     func(ctx context.Context) error
+		wantErr error
+	}{
+		{
+			name:   "lets the owner change the visibility of the deck",
+			player: "dealer",
+			set: func(ctx context.Context) error {
+				_, err := s.SetDeckVisibility(ctx, uuid, faceUp)
+				return err
+			},
+		},
+		{
+			name:   "doesn't let a player change the visibility of the deck",
+			player: "alice",
+			set: func(ctx context.Context) error {
+				_, err := s.SetDeckVisibility(ctx, uuid, faceUp)
+				return err
+			},
+			wantErr: service.ErrVisibilityNotAllowed,
+		},
+		{
+			name: "doesn't let anyone without a player change the visibility of the deck",
+			set: func(ctx context.Context) error {
+				_, err := s.SetDeckVisibility(ctx, uuid, faceUp)
+				return err
+			},
+			wantErr: service.ErrVisibilityNotAllowed,
+		},
+		{
+			name:   "lets the owner change the visibility of a pile",
+			player: "dealer",
+			set: func(ctx context.Context) error {
+				_, err := s.SetPileVisibility(ctx, uuid, "discard", faceUp)
+				return err
+			},
+		},
+		{
+			name:   "doesn't let a player change the visibility of a pile",
+			player: "bob",
+			set: func(ctx context.Context) error {
+				_, err := s.SetPileVisibility(ctx, uuid, "discard", faceUp)
+				return err
+			},
+			wantErr: service.ErrVisibilityNotAllowed,
+		},
+		{
+			name:   "lets the owner change the visibility of a hand",
+			player: "dealer",
+			set: func(ctx context.Context) error {
+				_, err := s.SetHandVisibility(ctx, uuid, "alice", faceUp)
+				return err
+			},
+		},
+		{
+			name:   "lets a player change the visibility of their hand",
+			player: "bob",
+			set: func(ctx context.Context) error {
+				_, err := s.SetHandVisibility(ctx, uuid, "bob", faceUp)
+				return err
+			},
+		},
+		{
+			name:   "doesn't let a player change the visibility of the hand of another",
+			player: "bob",
+			set: func(ctx context.Context) error {
+				_, err := s.SetHandVisibility(ctx, uuid, "alice", faceUp)
+				return err
+			},
+			wantErr: service.ErrVisibilityNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.player != "" {
+				ctx = service.ContextWithPlayer(ctx, tt.player)
+			}
+			if err := tt.set(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("setting the visibility error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}